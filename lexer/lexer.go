@@ -148,16 +148,17 @@ func (l *Lexer) NextFixed(length int) (Token, error) {
 		Type:    EmptyToken,
 		Literal: make([]byte, length),
 	}
-	_, err := io.ReadFull(l.Reader, t.Literal)
+	n, err := io.ReadFull(l.Reader, t.Literal)
 	switch err {
 	case io.ErrUnexpectedEOF:
-		for _, c := range t.Literal[:length-1] {
+		// only the bytes read are returned
+		t.Literal = t.Literal[:n]
+		for _, c := range t.Literal {
 			t.byte(c)
 		}
-		t.Literal = t.Literal[:len(t.Literal)-1]
 		return t, io.EOF
 	case io.EOF:
-		t.Literal = t.Literal[:len(t.Literal)-1]
+		t.Literal = t.Literal[:0]
 		return t, err
 	case nil:
 	default:
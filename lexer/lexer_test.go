@@ -186,6 +186,21 @@ func TestLexerFixedTooShort(t *testing.T) {
 	assert.Equal(t, []byte("too-short"), token.Literal)
 }
 
+func TestLexerFixedTruncated(t *testing.T) {
+	frame := "ST300STT;1008"
+	lexer := Lexer{
+		Reader: strings.NewReader(frame),
+	}
+	token, err := lexer.NextFixed(9)
+	require.Nil(t, err)
+	assert.Equal(t, []byte("ST300STT;"), token.Literal)
+
+	token, err = lexer.NextFixed(10)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, DigitsToken, token.Type)
+	assert.Equal(t, []byte("1008"), token.Literal)
+}
+
 func TestLexerFixedEmpty(t *testing.T) {
 	frame := ""
 	lexer := Lexer{
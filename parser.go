@@ -0,0 +1,255 @@
+// Package suntech provides a parser for a stream with frames of different models (ST300 and ST600 families),
+// dispatching each frame to the parser of its model.
+package suntech
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+	"github.com/larixsource/suntech/st300"
+	"github.com/larixsource/suntech/st600"
+)
+
+var ErrUnknownHdr = errors.New("unknown HDR")
+
+// Family is the family of models of a frame, telling which parser decoded it.
+type Family int
+
+const (
+	UnknownFamily Family = iota
+
+	// ST300Family are the ST300 models (including ST340 and ST340LC), decoded by the st300 package
+	ST300Family
+
+	// ST600Family are the ST600 models, decoded by the st600 package
+	ST600Family
+)
+
+const (
+	// zipHdrOffset is the offset of the header in a zip frame (after the STX and the length).
+	zipHdrOffset = 3
+
+	// zipModelOffset is the offset of the model in a zip frame (after the STX, the length, the header and the DevID).
+	zipModelOffset = 9
+)
+
+// ParserOpts holds configuration options that affect the behavior of the parser
+type ParserOpts struct {
+	// SkipUnknownFrames indicates to the parser if a frame with a unknown HDR should be consumed from the
+	// underlying reader without stopping the parsing process.
+	SkipUnknownFrames bool
+}
+
+// Msg is a frame parsed by the parser of its family: ST300 or ST600 holds the message (the other one is nil). Frame
+// and ParsingError are the ones of the message, also set for the frames of an unknown family.
+type Msg struct {
+	Family Family
+
+	ST300 *st300.Msg
+	ST600 *st600.Msg
+
+	Frame []byte
+
+	ParsingError error
+}
+
+// Report returns the report of the message (from any family), or nil when it isn't a report.
+func (m *Msg) Report() st.Report {
+	switch {
+	case m.ST300 != nil:
+		return m.ST300.Report()
+	case m.ST600 != nil:
+		return m.ST600.Report()
+	default:
+		return nil
+	}
+}
+
+// Parse returns a Parser to parse the content of a reader.
+func Parse(r io.Reader, opts ParserOpts) *Parser {
+	rd := bufio.NewReader(r)
+	// both parsers share the buffered reader (bufio.NewReader returns it as is), so each one reads its frames
+	return &Parser{
+		rd: rd,
+		lex: &lexer.Lexer{
+			Reader: rd,
+		},
+		opts:  opts,
+		st300: st300.Parse(rd, st300.ParserOpts{SkipUnknownFrames: opts.SkipUnknownFrames}),
+		st600: st600.Parse(rd, st600.ParserOpts{SkipUnknownFrames: opts.SkipUnknownFrames}),
+	}
+}
+
+func ParseString(s string, opts ParserOpts) *Parser {
+	return Parse(strings.NewReader(s), opts)
+}
+
+func ParseBytes(b []byte, opts ParserOpts) *Parser {
+	return Parse(bytes.NewReader(b), opts)
+}
+
+// Parser is a parser of frames of the ST300 and ST600 families, detected from the header of each ascii frame
+// (ST300, or ST600 and SA200) and from the model of each zip frame.
+type Parser struct {
+	rd    *bufio.Reader
+	lex   *lexer.Lexer
+	opts  ParserOpts
+	st300 *st300.Parser
+	st600 *st600.Parser
+
+	last *Msg
+	err  error
+}
+
+// Next parses the next frame, returning false at the end of the underlying reader or when a read error occurs.
+// Bytes that don't belong to any frame are skipped until the start of the next plausible frame, and returned as a
+// Msg with a st.ErrSkippedBytes ParsingError.
+func (p *Parser) Next() bool {
+	if _, err := p.rd.Peek(1); err != nil {
+		if err != io.EOF {
+			p.err = err
+		}
+		return false
+	}
+
+	switch {
+	case st.ZipAhead(p.rd):
+		p.last = p.next(zipFamily(p.rd))
+	case st.AsciiHdrAhead(p.rd):
+		family := asciiFamily(p.rd)
+		if family == UnknownFamily {
+			p.last = p.parseUnknown()
+			break
+		}
+		p.last = p.next(family)
+	default:
+		skipped, err := st.SkipToFrame(p.rd, p.frameAhead)
+		if err != nil {
+			p.err = err
+		}
+		p.last = &Msg{
+			Frame:        skipped,
+			ParsingError: st.ErrSkippedBytes,
+		}
+	}
+	return p.err == nil
+}
+
+// next parses the next frame with the parser of the family. The zip frames of an unknown model are consumed by the
+// st300 parser, that reports the model as unsupported.
+func (p *Parser) next(family Family) *Msg {
+	if family == ST600Family {
+		p.st600.Next()
+		p.err = p.st600.Error()
+		msg := p.st600.Msg()
+		return &Msg{
+			Family:       ST600Family,
+			ST600:        msg,
+			Frame:        msg.Frame,
+			ParsingError: msg.ParsingError,
+		}
+	}
+
+	p.st300.Next()
+	p.err = p.st300.Error()
+	msg := p.st300.Msg()
+	if family == UnknownFamily {
+		return &Msg{
+			Frame:        msg.Frame,
+			ParsingError: msg.ParsingError,
+		}
+	}
+	return &Msg{
+		Family:       ST300Family,
+		ST300:        msg,
+		Frame:        msg.Frame,
+		ParsingError: msg.ParsingError,
+	}
+}
+
+// parseUnknown consumes an ascii frame of an unknown family: the whole frame when unknown frames are skipped, or just
+// its header.
+func (p *Parser) parseUnknown() *Msg {
+	msg := &Msg{
+		ParsingError: ErrUnknownHdr,
+	}
+	token, err := p.lex.NextFixed(9)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil || !p.opts.SkipUnknownFrames {
+		return msg
+	}
+	token, err = p.lex.Next(1024, st.EndOfFrame)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = fmt.Errorf("error reading unknown frame: %+v", err)
+	}
+	return msg
+}
+
+// frameAhead tells if the next bytes are the start of a zip frame or of an ascii frame of a known family (or of any
+// family, when unknown frames are skipped).
+func (p *Parser) frameAhead() bool {
+	if st.ZipAhead(p.rd) {
+		return true
+	}
+	if !st.AsciiHdrAhead(p.rd) {
+		return false
+	}
+	return p.opts.SkipUnknownFrames || asciiFamily(p.rd) != UnknownFamily
+}
+
+func (p *Parser) Msg() *Msg {
+	return p.last
+}
+
+func (p *Parser) Error() error {
+	return p.err
+}
+
+// asciiFamily tells the family of the next ascii frame, from its header.
+func asciiFamily(rd *bufio.Reader) Family {
+	b, err := rd.Peek(5)
+	if err != nil {
+		return UnknownFamily
+	}
+	switch string(b[1:]) {
+	case "T300":
+		return ST300Family
+	case "T600", "A200":
+		return ST600Family
+	default:
+		return UnknownFamily
+	}
+}
+
+// zipFamily tells the family of the next zip frame, from its model. The frames without a model (the Alive Report,
+// only sent by the ST600 family) are told from their header.
+func zipFamily(rd *bufio.Reader) Family {
+	b, err := rd.Peek(zipHdrOffset + 1)
+	if err != nil {
+		return UnknownFamily
+	}
+	if b[zipHdrOffset] == st.ZipALV {
+		return ST600Family
+	}
+
+	b, err = rd.Peek(zipModelOffset + 1)
+	if err != nil {
+		return UnknownFamily
+	}
+	model := st.Model(b[zipModelOffset])
+	switch {
+	case st300.KnownModel(model):
+		return ST300Family
+	case st600.KnownModel(model):
+		return ST600Family
+	default:
+		return UnknownFamily
+	}
+}
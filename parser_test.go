@@ -0,0 +1,163 @@
+package suntech
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/larixsource/suntech/st300"
+	"github.com/larixsource/suntech/st600"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	st300Frame = "ST300STT;100850000;01;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;001100;1;0072;0;4.5;1\r"
+	st600Frame = "ST600STT;100850000;20;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;00110000;1;0072;0;4.5;1;12.35\r"
+	dpaFrame   = "SA200DPA;Res;600850000;010;1;0.0;30.0;100.0;70.0;100.0\r"
+	otherFrame = "ST500CMD;Res;100850000;010;ReqIMSI;724031111553779\r"
+
+	// alvZipFrame doesn't have a model, its ETX is where the model of other zip frames is
+	alvZipFrame = "\x02\x00\x06\x14\x10\x08\x50\x00\x00\x03"
+)
+
+// zipFrames returns a zip frame of each family, encoding the status reports of the ascii frames.
+func zipFrames(t *testing.T) (zip300, zip600 string) {
+	p300 := st300.ParseString(st300Frame, st300.ParserOpts{})
+	require.True(t, p300.Next())
+	b, err := p300.Msg().STT.MarshalZip()
+	require.Nil(t, err)
+	zip300 = string(b)
+
+	p600 := st600.ParseString(st600Frame, st600.ParserOpts{})
+	require.True(t, p600.Next())
+	b, err = p600.Msg().STT.MarshalZip()
+	require.Nil(t, err)
+	zip600 = string(b)
+	return
+}
+
+func TestParseMixed(t *testing.T) {
+	zip300, zip600 := zipFrames(t)
+	frames := []struct {
+		frame  string
+		family Family
+	}{
+		{frame: st300Frame, family: ST300Family},
+		{frame: zip600, family: ST600Family},
+		{frame: st600Frame, family: ST600Family},
+		{frame: zip300, family: ST300Family},
+		{frame: dpaFrame, family: ST600Family},
+		{frame: alvZipFrame, family: ST600Family},
+		{frame: otherFrame, family: UnknownFamily},
+		{frame: st300Frame, family: ST300Family},
+	}
+	var stream string
+	for _, f := range frames {
+		stream += f.frame
+	}
+
+	p := ParseString(stream, ParserOpts{SkipUnknownFrames: true})
+	for i, f := range frames {
+		require.True(t, p.Next(), "frame %d", i)
+		msg := p.Msg()
+		assert.Equal(t, f.frame, string(msg.Frame), "frame %d", i)
+		assert.Equal(t, f.family, msg.Family, "frame %d", i)
+		switch f.family {
+		case ST300Family:
+			require.NotNil(t, msg.ST300)
+			assert.Nil(t, msg.ST600)
+			assert.Nil(t, msg.ParsingError)
+			assert.Equal(t, st300.STTReport, msg.ST300.Type)
+		case ST600Family:
+			require.NotNil(t, msg.ST600)
+			assert.Nil(t, msg.ST300)
+			assert.Nil(t, msg.ParsingError)
+		default:
+			assert.Equal(t, ErrUnknownHdr, msg.ParsingError)
+		}
+	}
+	assert.False(t, p.Next())
+	assert.Nil(t, p.Error())
+}
+
+func TestParseZipALV(t *testing.T) {
+	p := ParseString(alvZipFrame+st300Frame, ParserOpts{})
+	require.True(t, p.Next())
+	msg := p.Msg()
+	assert.Equal(t, ST600Family, msg.Family)
+	assert.Nil(t, msg.ParsingError)
+	require.NotNil(t, msg.ST600)
+	require.NotNil(t, msg.ST600.ALV)
+	assert.Equal(t, "100850000", msg.ST600.ALV.DevID)
+
+	require.True(t, p.Next())
+	assert.Equal(t, ST300Family, p.Msg().Family)
+	assert.False(t, p.Next())
+}
+
+func TestParseSkippedBytes(t *testing.T) {
+	p := ParseString("garbage"+st600Frame+"ST500CMD;"+st300Frame, ParserOpts{})
+
+	require.True(t, p.Next())
+	assert.Equal(t, st.ErrSkippedBytes, p.Msg().ParsingError)
+	assert.Equal(t, "garbage", string(p.Msg().Frame))
+
+	require.True(t, p.Next())
+	assert.Equal(t, ST600Family, p.Msg().Family)
+
+	// without skipping unknown frames, only the header is consumed, and the rest is skipped
+	require.True(t, p.Next())
+	assert.Equal(t, ErrUnknownHdr, p.Msg().ParsingError)
+	assert.Equal(t, "ST500CMD;", string(p.Msg().Frame))
+
+	require.True(t, p.Next())
+	assert.Equal(t, ST300Family, p.Msg().Family)
+	assert.Equal(t, st300Frame, string(p.Msg().Frame))
+	assert.False(t, p.Next())
+}
+
+func TestParseUnknownZipModel(t *testing.T) {
+	_, zip600 := zipFrames(t)
+	b := []byte(zip600)
+	b[zipModelOffset] = 0xEE
+
+	p := ParseBytes(b, ParserOpts{})
+	require.True(t, p.Next())
+	assert.Equal(t, UnknownFamily, p.Msg().Family)
+	assert.Equal(t, b, p.Msg().Frame)
+	assert.Equal(t, st.ErrUnsupportedModel, p.Msg().ParsingError)
+	assert.False(t, p.Next())
+}
+
+func TestMsgReport(t *testing.T) {
+	p := ParseString(st300Frame+st600Frame+dpaFrame, ParserOpts{})
+
+	require.True(t, p.Next())
+	r300 := p.Msg().Report()
+	require.NotNil(t, r300)
+	require.True(t, p.Next())
+	r600 := p.Msg().Report()
+	require.NotNil(t, r600)
+
+	// both frames have the same position and readings
+	c300, c600 := r300.Common(), r600.Common()
+	assert.Equal(t, st.ST300, c300.Model)
+	assert.Equal(t, st.ST600R, c600.Model)
+	assert.Equal(t, "001100", c300.IO)
+	assert.Equal(t, "00110000", c600.IO)
+	for _, c := range []st.CommonReport{c300, c600} {
+		assert.Equal(t, "100850000", c.DevID)
+		assert.EqualValues(t, 10, c.SwVer)
+		assert.Equal(t, "00100", c.Cell)
+		assert.InDelta(t, 37.478519, c.Latitude, 1e-5)
+		assert.InDelta(t, 126.886819, c.Longitude, 1e-5)
+		assert.EqualValues(t, 9, c.Satellites)
+		assert.True(t, c.GPSFixed)
+		assert.Equal(t, float32(15.30), c.PowerVolt)
+		assert.EqualValues(t, 4.5, c.BackupVolt)
+	}
+
+	require.True(t, p.Next())
+	assert.Nil(t, p.Msg().Report())
+	assert.Nil(t, (&Msg{}).Report())
+}
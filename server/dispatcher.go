@@ -0,0 +1,303 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/larixsource/suntech"
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+	"github.com/larixsource/suntech/st300"
+	"github.com/larixsource/suntech/st600"
+)
+
+var (
+	ErrInvalidCommand = errors.New("invalid command frame")
+	ErrCommandTimeout = errors.New("command timeout")
+)
+
+// Dispatcher sends commands to the devices and waits for their responses: the frames with the header of the
+// command, "Res" and the DevID of the device (like "ST300CGF;Res;100850000;..."), or the next Status Report of the
+// device for the StatusReq command. The responses are also delivered to the handler of the server.
+type Dispatcher struct {
+	srv  *Server
+	next Handler
+
+	// Timeout is the maximum time to wait for the response of a command, including the time waiting for the device
+	// to connect. Zero means no timeout (other than the one of the context).
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string][]chan *suntech.Msg
+	online  map[string]chan struct{}
+}
+
+// NewDispatcher returns a Dispatcher for the sessions of a server, installing it as the handler of the server (the
+// previous one still handles every message). It must be called before serving.
+func NewDispatcher(srv *Server) *Dispatcher {
+	d := &Dispatcher{
+		srv:     srv,
+		next:    srv.Handler,
+		pending: make(map[string][]chan *suntech.Msg),
+		online:  make(map[string]chan struct{}),
+	}
+	srv.Handler = d
+	return d
+}
+
+// Handle takes the responses of the pending commands, and delivers every message to the previous handler.
+func (d *Dispatcher) Handle(s *Session, msg *suntech.Msg) {
+	if devID := s.DevID(); devID != "" {
+		d.notifyOnline(devID)
+	}
+	if key, _, ok := responseKey(msg); ok {
+		d.respond(key, msg)
+	}
+	if d.next != nil {
+		d.next.Handle(s, msg)
+	}
+}
+
+// Send sends a command frame (like the ones built by the Command method of the commands) to its device, and returns
+// the message of its response, with the typed response in its ST300 or ST600 message (like ST300.CGF).
+//
+// When the device isn't connected, Send waits for its next connection. When the connection is closed before the
+// response arrives, the command is sent again on the next one. It fails with ErrCommandTimeout after the Timeout,
+// or with the error of the context. The write of the frame is only limited by the WriteTimeout of the server.
+func (d *Dispatcher) Send(ctx context.Context, frame []byte) (*suntech.Msg, error) {
+	key, devID, err := commandKey(frame)
+	if err != nil {
+		return nil, err
+	}
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+
+	resp := d.wait(key)
+	defer d.cancel(key, resp)
+	for {
+		// the response could arrive along with the end of the session (or the context), and the commands aren't
+		// always idempotent (like Reset), so it's taken before sending the command again
+		select {
+		case msg := <-resp:
+			return msg, nil
+		default:
+		}
+		if ctx.Err() != nil {
+			return nil, contextErr(ctx)
+		}
+		s, err := d.session(ctx, devID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Send(frame); err != nil {
+			// the command is sent again on the next session
+			s.Close()
+			continue
+		}
+		select {
+		case msg := <-resp:
+			return msg, nil
+		case <-s.Done():
+		case <-ctx.Done():
+			return nil, contextErr(ctx)
+		}
+	}
+}
+
+// session returns the session of a device, waiting for its connection.
+func (d *Dispatcher) session(ctx context.Context, devID string) (*Session, error) {
+	for {
+		online := d.waitOnline(devID)
+		if s, ok := d.srv.Session(devID); ok && !s.isClosed() {
+			return s, nil
+		}
+		select {
+		case <-online:
+		case <-ctx.Done():
+			return nil, contextErr(ctx)
+		}
+	}
+}
+
+func contextErr(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return ErrCommandTimeout
+	}
+	return ctx.Err()
+}
+
+// wait registers a pending command, returning the channel of its response.
+func (d *Dispatcher) wait(key string) chan *suntech.Msg {
+	resp := make(chan *suntech.Msg, 1)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending[key] = append(d.pending[key], resp)
+	return resp
+}
+
+// cancel removes a pending command (if its response hasn't arrived).
+func (d *Dispatcher) cancel(key string, resp chan *suntech.Msg) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pending := d.pending[key]
+	for i, c := range pending {
+		if c == resp {
+			pending = append(pending[:i:i], pending[i+1:]...)
+			break
+		}
+	}
+	if len(pending) == 0 {
+		delete(d.pending, key)
+	} else {
+		d.pending[key] = pending
+	}
+}
+
+// respond delivers a response to the oldest pending command of its key. Responses without a pending command are
+// ignored.
+func (d *Dispatcher) respond(key string, msg *suntech.Msg) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pending := d.pending[key]
+	if len(pending) == 0 {
+		return
+	}
+	pending[0] <- msg
+	if len(pending) == 1 {
+		delete(d.pending, key)
+	} else {
+		d.pending[key] = pending[1:]
+	}
+}
+
+// waitOnline returns a channel closed when the next message of a device arrives.
+func (d *Dispatcher) waitOnline(devID string) chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	online, ok := d.online[devID]
+	if !ok {
+		online = make(chan struct{})
+		d.online[devID] = online
+	}
+	return online
+}
+
+func (d *Dispatcher) notifyOnline(devID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if online, ok := d.online[devID]; ok {
+		close(online)
+		delete(d.online, devID)
+	}
+}
+
+// commandKey returns the key of the responses of a command frame ("HDR;DevID;..."), and its DevID. The key is the
+// header and the DevID, plus the action for the CMD commands (like "ST300CMD;100850000;Reset").
+func commandKey(frame []byte) (key, devID string, err error) {
+	lex := &lexer.Lexer{
+		Reader: bytes.NewReader(frame),
+	}
+	hdr, err := lex.NextFixed(9)
+	if err != nil || !hdr.EndsWith(st.Separator) {
+		return "", "", ErrInvalidCommand
+	}
+	devID, _, err = st.AsciiDevID(lex)
+	if err != nil {
+		return "", "", ErrInvalidCommand
+	}
+	key = string(hdr.Literal) + devID
+	if isCMD(hdr) {
+		action, ok := cmdAction(frame)
+		if !ok {
+			return "", "", ErrInvalidCommand
+		}
+		if action == st.StatusReqCmd {
+			return statusKey(string(hdr.Literal[:5]), devID), devID, nil
+		}
+		key += ";" + action.String()
+	}
+	return key, devID, nil
+}
+
+// responseKey returns the key of a response ("HDR;Res;DevID;..."), and its DevID. A Status Report is the response
+// of the StatusReq command. The messages with a ParsingError aren't responses.
+func responseKey(msg *suntech.Msg) (key, devID string, ok bool) {
+	if msg.ParsingError != nil {
+		return "", "", false
+	}
+	if isSTT(msg) {
+		model := "ST300"
+		if msg.Family == suntech.ST600Family {
+			model = "ST600"
+		}
+		devID = MsgDevID(msg)
+		return statusKey(model, devID), devID, devID != ""
+	}
+
+	lex := &lexer.Lexer{
+		Reader: bytes.NewReader(msg.Frame),
+	}
+	hdr, err := lex.NextFixed(9)
+	if err != nil || !hdr.EndsWith(st.Separator) {
+		return "", "", false
+	}
+	isDevID, _, _, err := st.AsciiDevIDOrRes(lex)
+	if err != nil || isDevID {
+		return "", "", false
+	}
+	devID, _, err = st.AsciiDevID(lex)
+	if err != nil {
+		return "", "", false
+	}
+	key = string(hdr.Literal) + devID
+	if isCMD(hdr) {
+		action, ok := cmdAction(msg.Frame)
+		if !ok {
+			return "", "", false
+		}
+		key += ";" + action.String()
+	}
+	return key, devID, true
+}
+
+// statusKey returns the key of the Status Reports of a device, given the model of its headers ("ST300" or "ST600").
+func statusKey(model, devID string) string {
+	return model + "STT;" + devID
+}
+
+func isSTT(msg *suntech.Msg) bool {
+	switch {
+	case msg.ST300 != nil:
+		return msg.ST300.Type == st300.STTReport
+	case msg.ST600 != nil:
+		return msg.ST600.Type == st600.STTReport
+	default:
+		return false
+	}
+}
+
+func isCMD(hdr lexer.Token) bool {
+	return bytes.HasSuffix(hdr.Literal, []byte("CMD;"))
+}
+
+// cmdAction returns the action of a CMD command ("HDR;DevID;Ver;Action") or response ("HDR;Res;DevID;SwVer;Action;...",
+// where the SwVer could be missing).
+func cmdAction(frame []byte) (st.CmdAction, bool) {
+	fields := st.SplitParams(bytes.TrimSuffix(frame, []byte{st.EndOfFrame}))
+	resp, _, _, rest, err := st.DecodeCmdHdr(fields[1:])
+	if err != nil || len(rest) == 0 {
+		return st.UnknownCmd, false
+	}
+	if resp {
+		return st.CmdResultAction(rest[0])
+	}
+	cmd, err := st.DecodeCmd(strings.TrimSpace(rest[0]))
+	return cmd.Action, err == nil
+}
@@ -0,0 +1,260 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/larixsource/suntech"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cgfCommand    = "ST300CGF;100850000;02;1;1;+37.000000;+127.000000;50;1;1"
+	cgfResponse   = "ST300CGF;Res;100850000;010;1;1;+37.000000;+127.000000;50;1;1\r"
+	rptCommand    = "ST600RPT;200850000;02;180;120;60;3;0;0;0;0;0"
+	rptResponse   = "ST600RPT;Res;200850000;010;180;120;60;3;0;0;0;0;0\r"
+	resetResponse = "ST300CMD;Res;100850000;010;Reset\r"
+)
+
+type sent struct {
+	msg *suntech.Msg
+	err error
+}
+
+func send(d *Dispatcher, frame string) chan sent {
+	ch := make(chan sent, 1)
+	go func() {
+		msg, err := d.Send(context.Background(), []byte(frame))
+		ch <- sent{msg: msg, err: err}
+	}()
+	return ch
+}
+
+// connect connects a device to the server, sending its first report.
+func connect(t *testing.T, srv *Server, report string) (net.Conn, *bufio.Reader) {
+	device, _ := pipe(srv)
+	_, err := device.Write([]byte(report))
+	require.Nil(t, err)
+	return device, bufio.NewReader(device)
+}
+
+func TestFrameKeys(t *testing.T) {
+	key, devID, err := commandKey([]byte(cgfCommand))
+	require.Nil(t, err)
+	assert.Equal(t, "ST300CGF;100850000", key)
+	assert.Equal(t, "100850000", devID)
+
+	_, _, err = commandKey([]byte("ST300CGF;Res;100850000"))
+	assert.Equal(t, ErrInvalidCommand, err)
+	_, _, err = commandKey([]byte("ST300"))
+	assert.Equal(t, ErrInvalidCommand, err)
+
+	p := suntech.ParseString(cgfResponse+emg300Frame, suntech.ParserOpts{})
+	require.True(t, p.Next())
+	key, devID, ok := responseKey(p.Msg())
+	assert.True(t, ok)
+	assert.Equal(t, "ST300CGF;100850000", key)
+	assert.Equal(t, "100850000", devID)
+	require.True(t, p.Next())
+	_, _, ok = responseKey(p.Msg())
+	assert.False(t, ok)
+
+	// StatusReq is answered by a Status Report
+	key, _, err = commandKey([]byte("ST300CMD;100850000;02;StatusReq"))
+	require.Nil(t, err)
+	assert.Equal(t, "ST300STT;100850000", key)
+	for frame, expected := range map[string]string{
+		st300Frame: "ST300STT;100850000",
+		st600Frame: "ST600STT;200850000",
+	} {
+		p = suntech.ParseString(frame, suntech.ParserOpts{})
+		require.True(t, p.Next())
+		key, _, ok = responseKey(p.Msg())
+		assert.True(t, ok, frame)
+		assert.Equal(t, expected, key, frame)
+	}
+
+	// the CMD keys include the action
+	key, _, err = commandKey([]byte(resetCommand))
+	require.Nil(t, err)
+	assert.Equal(t, "ST300CMD;100850000;Reset", key)
+	_, _, err = commandKey([]byte("ST300CMD;100850000;02;Unknown"))
+	assert.Equal(t, ErrInvalidCommand, err)
+	for frame, expected := range map[string]string{
+		resetResponse:                           "ST300CMD;100850000;Reset",
+		ack300Frame:                             "ST300CMD;100850000;AckEmerg",
+		"ST600CMD;Res;100850000;Enable1NoUse\r": "ST600CMD;100850000;Enable1",
+		"ST300CMD;Res;100850000;010;SetOdometer=10\r": "ST300CMD;100850000;SetOdometer",
+	} {
+		p = suntech.ParseString(frame, suntech.ParserOpts{})
+		require.True(t, p.Next())
+		key, _, ok = responseKey(p.Msg())
+		assert.True(t, ok, frame)
+		assert.Equal(t, expected, key, frame)
+	}
+
+	// a response with a ParsingError doesn't answer the command
+	p = suntech.ParseString("ST300CGF;Res;100850000;010;X\r", suntech.ParserOpts{})
+	require.True(t, p.Next())
+	require.NotNil(t, p.Msg().ParsingError)
+	_, _, ok = responseKey(p.Msg())
+	assert.False(t, ok)
+}
+
+func TestDispatcherSend(t *testing.T) {
+	srv, ch := testServer()
+	d := NewDispatcher(srv)
+	device, rd := connect(t, srv, st300Frame)
+	defer device.Close()
+	receive(t, ch)
+
+	result := send(d, cgfCommand)
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, cgfCommand+"\r", frame)
+
+	// other frames don't answer the command
+	_, err = device.Write([]byte(st300Frame + cgfResponse))
+	require.Nil(t, err)
+	r := <-result
+	require.Nil(t, r.err)
+	require.NotNil(t, r.msg.ST300)
+	require.NotNil(t, r.msg.ST300.CGF)
+	assert.Equal(t, cgfResponse, string(r.msg.Frame))
+
+	// every message still reaches the handler
+	assert.Equal(t, st300Frame, string(receive(t, ch).msg.Frame))
+	assert.Equal(t, cgfResponse, string(receive(t, ch).msg.Frame))
+}
+
+func TestDispatcherSendST600(t *testing.T) {
+	srv, _ := testServer()
+	d := NewDispatcher(srv)
+	device, rd := connect(t, srv, st600Frame)
+	defer device.Close()
+
+	result := send(d, rptCommand)
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, rptCommand+"\r", frame)
+	_, err = device.Write([]byte(rptResponse))
+	require.Nil(t, err)
+	r := <-result
+	require.Nil(t, r.err)
+	require.NotNil(t, r.msg.ST600)
+	require.NotNil(t, r.msg.ST600.RPT)
+	assert.Equal(t, rptResponse, string(r.msg.Frame))
+}
+
+func TestDispatcherStatusReq(t *testing.T) {
+	srv, ch := testServer()
+	d := NewDispatcher(srv)
+	device, rd := connect(t, srv, st300Frame)
+	defer device.Close()
+	receive(t, ch)
+
+	result := send(d, "ST300CMD;100850000;02;StatusReq")
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, "ST300CMD;100850000;02;StatusReq\r", frame)
+	_, err = device.Write([]byte(emg300Frame + st300Frame))
+	require.Nil(t, err)
+	r := <-result
+	require.Nil(t, r.err)
+	require.NotNil(t, r.msg.ST300)
+	require.NotNil(t, r.msg.ST300.STT)
+	assert.Equal(t, st300Frame, string(r.msg.Frame))
+}
+
+func TestDispatcherCmdAction(t *testing.T) {
+	srv, _ := testServer()
+	d := NewDispatcher(srv)
+	device, rd := connect(t, srv, st300Frame)
+	defer device.Close()
+
+	result := send(d, resetCommand)
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, resetCommand+"\r", frame)
+
+	// the response of another CMD command doesn't answer the Reset
+	_, err = device.Write([]byte(ack300Frame + resetResponse))
+	require.Nil(t, err)
+	r := <-result
+	require.Nil(t, r.err)
+	assert.Equal(t, resetResponse, string(r.msg.Frame))
+}
+
+func TestDispatcherOffline(t *testing.T) {
+	srv, ch := testServer()
+	d := NewDispatcher(srv)
+
+	result := send(d, cgfCommand)
+	device, rd := connect(t, srv, st300Frame)
+	defer device.Close()
+	receive(t, ch)
+
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, cgfCommand+"\r", frame)
+	_, err = device.Write([]byte(cgfResponse))
+	require.Nil(t, err)
+	r := <-result
+	require.Nil(t, r.err)
+	assert.Equal(t, cgfResponse, string(r.msg.Frame))
+}
+
+func TestDispatcherRetry(t *testing.T) {
+	srv, _ := testServer()
+	d := NewDispatcher(srv)
+	device1, rd1 := connect(t, srv, st300Frame)
+
+	result := send(d, cgfCommand)
+	frame, err := rd1.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, cgfCommand+"\r", frame)
+
+	// the connection is lost before the response, so the command is sent again on the next one
+	device1.Close()
+	device2, rd2 := connect(t, srv, st300Frame)
+	defer device2.Close()
+	frame, err = rd2.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, cgfCommand+"\r", frame)
+	_, err = device2.Write([]byte(cgfResponse))
+	require.Nil(t, err)
+	r := <-result
+	require.Nil(t, r.err)
+	assert.Equal(t, cgfResponse, string(r.msg.Frame))
+}
+
+func TestDispatcherTimeout(t *testing.T) {
+	srv, _ := testServer()
+	d := NewDispatcher(srv)
+	d.Timeout = 20 * time.Millisecond
+
+	// the device is offline
+	_, err := d.Send(context.Background(), []byte(cgfCommand))
+	assert.Equal(t, ErrCommandTimeout, err)
+
+	// the device doesn't answer
+	device, rd := connect(t, srv, st300Frame)
+	defer device.Close()
+	go io.Copy(io.Discard, rd)
+	_, err = d.Send(context.Background(), []byte(cgfCommand))
+	assert.Equal(t, ErrCommandTimeout, err)
+	assert.Empty(t, d.pending)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = d.Send(ctx, []byte(cgfCommand))
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = d.Send(context.Background(), []byte("ST300CGF;Res"))
+	assert.Equal(t, ErrInvalidCommand, err)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"time"
+
+	"github.com/larixsource/suntech"
+	"github.com/larixsource/suntech/st"
+	"github.com/larixsource/suntech/st300"
+	"github.com/larixsource/suntech/st600"
+)
+
+// emergencyRepeat is the maximum time between the repeated frames of an emergency. A later EMG frame is taken as a
+// new emergency, so the emergencies older than it are forgotten.
+const emergencyRepeat = 10 * time.Minute
+
+// EmergencyAck is the acknowledgement of the last emergency of a device, when the server acknowledges them
+// (AckEmergencies).
+type EmergencyAck struct {
+	EmgID st.EmergencyType
+
+	// Received is the time of the first EMG frame of the emergency
+	Received time.Time
+
+	// Frames is the count of EMG frames of the emergency, including the repeated ones
+	Frames int
+
+	// Sent is the last time the AckEmerg command was sent (zero when it couldn't be sent)
+	Sent time.Time
+
+	// Err is the error of the last AckEmerg command
+	Err error
+
+	// Acknowledged is true when the device has answered the AckEmerg command
+	Acknowledged bool
+
+	// last is the time of the last EMG frame
+	last time.Time
+}
+
+// EmergencyAck returns the acknowledgement of the last emergency of the device, when the server acknowledges them.
+// Called by the handler on an Emergency Report, it tells if the AckEmerg command was sent.
+func (s *Session) EmergencyAck() (EmergencyAck, bool) {
+	devID := s.DevID()
+	s.srv.mu.Lock()
+	defer s.srv.mu.Unlock()
+	e, ok := s.srv.emergencies[devID]
+	if !ok {
+		return EmergencyAck{}, false
+	}
+	return *e, true
+}
+
+// ackEmergency sends the AckEmerg command on an Emergency Report, and tracks the responses of the device. It returns
+// false for the repeated EMG frames of an emergency not acknowledged yet by the device (the AckEmerg command is
+// sent again, but they aren't handled).
+func (srv *Server) ackEmergency(s *Session, msg *suntech.Msg) bool {
+	if isAckEmergResponse(msg) {
+		srv.mu.Lock()
+		if e, ok := srv.emergencies[s.DevID()]; ok {
+			e.Acknowledged = true
+		}
+		srv.mu.Unlock()
+		return true
+	}
+
+	emgID, ok := emergencyOf(msg)
+	if !ok {
+		return true
+	}
+	devID := MsgDevID(msg)
+	now := time.Now()
+
+	srv.mu.Lock()
+	if srv.emergencies == nil {
+		srv.emergencies = make(map[string]*EmergencyAck)
+	}
+	srv.forgetEmergencies(now)
+	e, ok := srv.emergencies[devID]
+	repeated := ok && !e.Acknowledged && e.EmgID == emgID && now.Sub(e.last) < emergencyRepeat
+	if !repeated {
+		e = &EmergencyAck{
+			EmgID:    emgID,
+			Received: now,
+		}
+		srv.emergencies[devID] = e
+	}
+	e.Frames++
+	e.last = now
+	srv.mu.Unlock()
+
+	frame, err := ackEmergCommand(msg.Family, devID)
+	if err == nil {
+		err = s.Send(frame)
+	}
+
+	srv.mu.Lock()
+	e.Err = err
+	if err == nil {
+		e.Sent = time.Now()
+	}
+	srv.mu.Unlock()
+	return !repeated
+}
+
+// forgetEmergencies removes the emergencies whose last EMG frame is older than emergencyRepeat, as their repeated
+// frames won't come anymore. srv.mu must be held.
+func (srv *Server) forgetEmergencies(now time.Time) {
+	for devID, e := range srv.emergencies {
+		if now.Sub(e.last) >= emergencyRepeat {
+			delete(srv.emergencies, devID)
+		}
+	}
+}
+
+// emergencyOf returns the EmgID of an Emergency Report.
+func emergencyOf(msg *suntech.Msg) (st.EmergencyType, bool) {
+	switch {
+	case msg.ParsingError != nil:
+		return 0, false
+	case msg.ST300 != nil && msg.ST300.EMG != nil:
+		return msg.ST300.EMG.EmgID, true
+	case msg.ST600 != nil && msg.ST600.EMG != nil:
+		return msg.ST600.EMG.EmgID, true
+	default:
+		return 0, false
+	}
+}
+
+func isAckEmergResponse(msg *suntech.Msg) bool {
+	switch {
+	case msg.ParsingError != nil:
+		return false
+	case msg.ST300 != nil && msg.ST300.CMD != nil:
+		return msg.ST300.CMD.Resp && msg.ST300.CMD.Action == st.AckEmergCmd
+	case msg.ST600 != nil && msg.ST600.CMD != nil:
+		return msg.ST600.CMD.Resp && msg.ST600.CMD.Action == st.AckEmergCmd
+	default:
+		return false
+	}
+}
+
+// ackEmergCommand builds the AckEmerg command of the family of a device.
+func ackEmergCommand(family suntech.Family, devID string) ([]byte, error) {
+	ack := st.Cmd{Action: st.AckEmergCmd}
+	if family == suntech.ST600Family {
+		cmd := &st600.ST600CMD{DevID: devID, Cmd: ack}
+		return cmd.Command()
+	}
+	cmd := &st300.ST300CMD{DevID: devID, Cmd: ack}
+	return cmd.Command()
+}
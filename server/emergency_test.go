@@ -0,0 +1,119 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	emg300Frame = "ST300EMG;100850000;01;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;001100;1;000000;4.5;1\r"
+	emg600Frame = "ST600EMG;205951725;20;325;20151223;13:32:30;001cbf75;730;2;4e39;33;-32.500000;-071.125000;000.122;000.00;5;1;190269102;12.89;000000;1;183230;4.5;0;0.00\r"
+	ack300Frame = "ST300CMD;Res;100850000;010;AckEmerg\r"
+)
+
+func TestAckEmergency(t *testing.T) {
+	srv, ch := testServer()
+	srv.AckEmergencies = true
+	device, rd := connect(t, srv, emg300Frame)
+	defer device.Close()
+
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, "ST300CMD;100850000;02;AckEmerg\r", frame)
+	r := receive(t, ch)
+	assert.Equal(t, emg300Frame, string(r.msg.Frame))
+	ack, ok := r.session.EmergencyAck()
+	require.True(t, ok)
+	assert.Equal(t, st.PanicButtonEmg, ack.EmgID)
+	assert.Equal(t, 1, ack.Frames)
+	assert.Nil(t, ack.Err)
+	assert.False(t, ack.Sent.IsZero())
+	assert.False(t, ack.Acknowledged)
+
+	// the repeated frame is acknowledged again, but not handled
+	_, err = device.Write([]byte(emg300Frame))
+	require.Nil(t, err)
+	frame, err = rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, "ST300CMD;100850000;02;AckEmerg\r", frame)
+
+	_, err = device.Write([]byte(ack300Frame))
+	require.Nil(t, err)
+	r = receive(t, ch)
+	assert.Equal(t, ack300Frame, string(r.msg.Frame))
+	ack, ok = r.session.EmergencyAck()
+	require.True(t, ok)
+	assert.Equal(t, 2, ack.Frames)
+	assert.True(t, ack.Acknowledged)
+
+	// after the response, an EMG frame is a new emergency
+	_, err = device.Write([]byte(emg300Frame))
+	require.Nil(t, err)
+	_, err = rd.ReadString('\r')
+	require.Nil(t, err)
+	r = receive(t, ch)
+	assert.Equal(t, emg300Frame, string(r.msg.Frame))
+	ack, ok = r.session.EmergencyAck()
+	require.True(t, ok)
+	assert.Equal(t, 1, ack.Frames)
+	assert.False(t, ack.Acknowledged)
+}
+
+func TestAckEmergencyST600(t *testing.T) {
+	srv, ch := testServer()
+	srv.AckEmergencies = true
+	device, rd := connect(t, srv, emg600Frame)
+	defer device.Close()
+
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, "ST600CMD;205951725;02;AckEmerg\r", frame)
+	r := receive(t, ch)
+	ack, ok := r.session.EmergencyAck()
+	require.True(t, ok)
+	assert.Equal(t, st.PanicButtonEmg, ack.EmgID)
+}
+
+func TestAckEmergencyExpired(t *testing.T) {
+	srv, ch := testServer()
+	srv.AckEmergencies = true
+	device1, rd1 := connect(t, srv, emg300Frame)
+	defer device1.Close()
+	_, err := rd1.ReadString('\r')
+	require.Nil(t, err)
+	receive(t, ch)
+
+	srv.mu.Lock()
+	srv.emergencies["100850000"].last = time.Now().Add(-emergencyRepeat)
+	srv.mu.Unlock()
+
+	// the emergency is forgotten on the next one of any device
+	device2, rd2 := connect(t, srv, emg600Frame)
+	defer device2.Close()
+	_, err = rd2.ReadString('\r')
+	require.Nil(t, err)
+	receive(t, ch)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Len(t, srv.emergencies, 1)
+	assert.Contains(t, srv.emergencies, "205951725")
+}
+
+func TestAckEmergencyDisabled(t *testing.T) {
+	srv, ch := testServer()
+	device, _ := connect(t, srv, emg300Frame+emg300Frame)
+	defer device.Close()
+
+	// both frames are handled, without an AckEmerg
+	r := receive(t, ch)
+	assert.Equal(t, emg300Frame, string(r.msg.Frame))
+	r = receive(t, ch)
+	assert.Equal(t, emg300Frame, string(r.msg.Frame))
+	_, ok := r.session.EmergencyAck()
+	assert.False(t, ok)
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/larixsource/suntech"
+	"github.com/larixsource/suntech/st300"
+	"github.com/larixsource/suntech/st600"
+)
+
+// Queue keeps the commands of the devices in a Store until their responses arrive, for the devices that aren't
+// always connected. The commands of a device are sent when its next Status Report (or Alive Report) arrives, and
+// removed from the queue when their response is parsed without errors (the frame with the header of the command,
+// "Res" and the DevID of the device, or the next Status Report for the StatusReq command, as matched by Dispatcher).
+type Queue struct {
+	store Store
+	next  Handler
+
+	// ResendAfter is the time to wait for the response of a command before sending it again on the same session.
+	// Zero means that a command is only sent again on another session of the device.
+	ResendAfter time.Duration
+
+	// OnDone, if set, is called when the response of a queued command arrives, after removing it from the queue.
+	OnDone func(cmd *QueuedCommand, resp *suntech.Msg)
+
+	// OnError, if set, is called with the errors of the store.
+	OnError func(err error)
+
+	mu sync.Mutex
+	// sentOn holds the session where each command was sent the last time
+	sentOn map[uint64]*Session
+}
+
+// NewQueue returns a Queue for the sessions of a server, installing it as the handler of the server (the previous
+// one still handles every message). It must be called before serving.
+func NewQueue(srv *Server, store Store) *Queue {
+	q := &Queue{
+		store:  store,
+		next:   srv.Handler,
+		sentOn: make(map[uint64]*Session),
+	}
+	srv.Handler = q
+	return q
+}
+
+// Push adds a command frame (like the ones built by the Command method of the commands) to the queue of its device.
+func (q *Queue) Push(frame []byte) (*QueuedCommand, error) {
+	_, devID, err := commandKey(frame)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &QueuedCommand{
+		DevID:  devID,
+		Frame:  append([]byte(nil), frame...),
+		Queued: time.Now(),
+	}
+	if err := q.store.Push(cmd); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// Pending returns the queued commands of a device, in order.
+func (q *Queue) Pending(devID string) ([]*QueuedCommand, error) {
+	return q.store.Commands(devID)
+}
+
+// Handle sends the queued commands on the Status and Alive Reports, and takes the responses of the queued commands.
+// Every message is delivered to the previous handler.
+func (q *Queue) Handle(s *Session, msg *suntech.Msg) {
+	if key, devID, ok := responseKey(msg); ok {
+		q.respond(devID, key, msg)
+	}
+	if isSTTOrALV(msg) && s.DevID() != "" {
+		q.deliver(s)
+	}
+	if q.next != nil {
+		q.next.Handle(s, msg)
+	}
+}
+
+// deliver sends the queued commands of the device of a session, the ones not sent yet on the session (or sent more
+// than ResendAfter ago).
+func (q *Queue) deliver(s *Session) {
+	commands, err := q.store.Commands(s.DevID())
+	if err != nil {
+		q.fail(err)
+		return
+	}
+	for _, cmd := range commands {
+		if !q.shouldSend(cmd, s) {
+			continue
+		}
+		if err := s.Send(cmd.Frame); err != nil {
+			// the rest are sent on the next session
+			return
+		}
+		q.mu.Lock()
+		q.sentOn[cmd.ID] = s
+		q.mu.Unlock()
+		cmd.Sent = time.Now()
+		if err := q.store.Update(cmd); err != nil {
+			q.fail(err)
+		}
+	}
+}
+
+func (q *Queue) shouldSend(cmd *QueuedCommand, s *Session) bool {
+	q.mu.Lock()
+	sentOn := q.sentOn[cmd.ID]
+	q.mu.Unlock()
+	if sentOn != s {
+		return true
+	}
+	return q.ResendAfter > 0 && time.Since(cmd.Sent) >= q.ResendAfter
+}
+
+// respond removes from the queue the oldest command (already sent) with the key of a response.
+func (q *Queue) respond(devID, key string, msg *suntech.Msg) {
+	commands, err := q.store.Commands(devID)
+	if err != nil {
+		q.fail(err)
+		return
+	}
+	for _, cmd := range commands {
+		if cmd.Sent.IsZero() {
+			continue
+		}
+		cmdKey, _, err := commandKey(cmd.Frame)
+		if err != nil || cmdKey != key {
+			continue
+		}
+		if err := q.store.Remove(cmd.ID); err != nil {
+			q.fail(err)
+			return
+		}
+		q.mu.Lock()
+		delete(q.sentOn, cmd.ID)
+		q.mu.Unlock()
+		if q.OnDone != nil {
+			q.OnDone(cmd, msg)
+		}
+		return
+	}
+}
+
+func (q *Queue) fail(err error) {
+	if q.OnError != nil {
+		q.OnError(err)
+	}
+}
+
+func isSTTOrALV(msg *suntech.Msg) bool {
+	switch {
+	case msg.ParsingError != nil:
+		return false
+	case msg.ST300 != nil:
+		return msg.ST300.Type == st300.STTReport || msg.ST300.Type == st300.ALVReport
+	case msg.ST600 != nil:
+		return msg.ST600.Type == st600.STTReport || msg.ST600.Type == st600.ALVReport
+	default:
+		return false
+	}
+}
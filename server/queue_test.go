@@ -0,0 +1,267 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/larixsource/suntech"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const resetCommand = "ST300CMD;100850000;02;Reset"
+
+func testStore(t *testing.T, store Store) {
+	cmd1 := &QueuedCommand{DevID: "100850000", Frame: []byte(cgfCommand), Queued: time.Now()}
+	cmd2 := &QueuedCommand{DevID: "200850000", Frame: []byte("ST600CMD;200850000;02;Reset"), Queued: time.Now()}
+	cmd3 := &QueuedCommand{DevID: "100850000", Frame: []byte(resetCommand), Queued: time.Now()}
+	for _, cmd := range []*QueuedCommand{cmd1, cmd2, cmd3} {
+		require.Nil(t, store.Push(cmd))
+	}
+	assert.True(t, cmd1.ID < cmd2.ID && cmd2.ID < cmd3.ID)
+
+	commands, err := store.Commands("100850000")
+	require.Nil(t, err)
+	require.Len(t, commands, 2)
+	assert.Equal(t, cmd1.ID, commands[0].ID)
+	assert.Equal(t, cgfCommand, string(commands[0].Frame))
+	assert.Equal(t, cmd3.ID, commands[1].ID)
+
+	cmd1.Sent = time.Now()
+	require.Nil(t, store.Update(cmd1))
+	commands, err = store.Commands("100850000")
+	require.Nil(t, err)
+	assert.False(t, commands[0].Sent.IsZero())
+
+	require.Nil(t, store.Remove(cmd1.ID))
+	commands, err = store.Commands("100850000")
+	require.Nil(t, err)
+	require.Len(t, commands, 1)
+	assert.Equal(t, cmd3.ID, commands[0].ID)
+
+	assert.Equal(t, ErrUnknownCommand, store.Remove(cmd1.ID))
+	assert.Equal(t, ErrUnknownCommand, store.Update(cmd1))
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	store, err := NewFileStore(path)
+	require.Nil(t, err)
+	testStore(t, store)
+
+	// the queue survives a restart
+	store, err = NewFileStore(path)
+	require.Nil(t, err)
+	commands, err := store.Commands("100850000")
+	require.Nil(t, err)
+	require.Len(t, commands, 1)
+	assert.Equal(t, resetCommand, string(commands[0].Frame))
+	commands, err = store.Commands("200850000")
+	require.Nil(t, err)
+	require.Len(t, commands, 1)
+
+	// the IDs aren't reused
+	cmd := &QueuedCommand{DevID: "100850000", Frame: []byte(cgfCommand)}
+	require.Nil(t, store.Push(cmd))
+	assert.EqualValues(t, 4, cmd.ID)
+}
+
+func TestFileStoreSaveError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "queue")
+	require.Nil(t, os.Mkdir(dir, 0o755))
+	store, err := NewFileStore(filepath.Join(dir, "queue.json"))
+	require.Nil(t, err)
+	cmd := &QueuedCommand{DevID: "100850000", Frame: []byte(cgfCommand)}
+	require.Nil(t, store.Push(cmd))
+
+	// the changes that can't be saved are undone
+	require.Nil(t, os.RemoveAll(dir))
+	assert.NotNil(t, store.Push(&QueuedCommand{DevID: "100850000", Frame: []byte(resetCommand)}))
+	sent := *cmd
+	sent.Sent = time.Now()
+	assert.NotNil(t, store.Update(&sent))
+	assert.NotNil(t, store.Remove(cmd.ID))
+
+	commands, err := store.Commands("100850000")
+	require.Nil(t, err)
+	require.Len(t, commands, 1)
+	assert.Equal(t, cmd.ID, commands[0].ID)
+	assert.True(t, commands[0].Sent.IsZero())
+
+	// the IDs of the failed commands are assigned again
+	require.Nil(t, os.Mkdir(dir, 0o755))
+	cmd = &QueuedCommand{DevID: "100850000", Frame: []byte(resetCommand)}
+	require.Nil(t, store.Push(cmd))
+	assert.EqualValues(t, 2, cmd.ID)
+}
+
+type done struct {
+	cmd  *QueuedCommand
+	resp *suntech.Msg
+}
+
+func testQueue(store Store) (*Server, *Queue, chan done) {
+	srv, _ := testServer()
+	q := NewQueue(srv, store)
+	ch := make(chan done, 4)
+	q.OnDone = func(cmd *QueuedCommand, resp *suntech.Msg) {
+		ch <- done{cmd: cmd, resp: resp}
+	}
+	return srv, q, ch
+}
+
+func TestQueue(t *testing.T) {
+	srv, q, doneCh := testQueue(NewMemoryStore())
+	cgf, err := q.Push([]byte(cgfCommand))
+	require.Nil(t, err)
+	assert.Equal(t, "100850000", cgf.DevID)
+	_, err = q.Push([]byte(resetCommand))
+	require.Nil(t, err)
+	_, err = q.Push([]byte("ST300"))
+	assert.Equal(t, ErrInvalidCommand, err)
+
+	// the commands wait for a Status Report
+	device, rd := connect(t, srv, cmdFrame)
+	defer device.Close()
+	_, err = device.Write([]byte(st300Frame))
+	require.Nil(t, err)
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, cgfCommand+"\r", frame)
+	frame, err = rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, resetCommand+"\r", frame)
+
+	// the commands aren't sent again on the same session
+	_, err = device.Write([]byte(cgfResponse + st300Frame))
+	require.Nil(t, err)
+	d := <-doneCh
+	assert.Equal(t, cgf.ID, d.cmd.ID)
+	assert.Equal(t, cgfResponse, string(d.resp.Frame))
+
+	pending, err := q.Pending("100850000")
+	require.Nil(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, resetCommand, string(pending[0].Frame))
+	assert.False(t, pending[0].Sent.IsZero())
+}
+
+func TestQueueResponses(t *testing.T) {
+	srv, q, doneCh := testQueue(NewMemoryStore())
+	rpt, err := q.Push([]byte(rptCommand))
+	require.Nil(t, err)
+	reset, err := q.Push([]byte("ST600CMD;200850000;02;Reset"))
+	require.Nil(t, err)
+
+	device, rd := connect(t, srv, st600Frame)
+	defer device.Close()
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, rptCommand+"\r", frame)
+	_, err = rd.ReadString('\r')
+	require.Nil(t, err)
+
+	// the response of another CMD command doesn't answer the Reset
+	_, err = device.Write([]byte("ST600CMD;Res;200850000;010;AckEmerg\r" + rptResponse + "ST600CMD;Res;200850000;010;Reset\r"))
+	require.Nil(t, err)
+	d := <-doneCh
+	assert.Equal(t, rpt.ID, d.cmd.ID)
+	assert.Equal(t, rptResponse, string(d.resp.Frame))
+	d = <-doneCh
+	assert.Equal(t, reset.ID, d.cmd.ID)
+
+	pending, err := q.Pending("200850000")
+	require.Nil(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestQueueStatusReq(t *testing.T) {
+	srv, q, doneCh := testQueue(NewMemoryStore())
+	cgf, err := q.Push([]byte(cgfCommand))
+	require.Nil(t, err)
+	statusReq, err := q.Push([]byte("ST300CMD;100850000;02;StatusReq"))
+	require.Nil(t, err)
+
+	device, rd := connect(t, srv, st300Frame)
+	defer device.Close()
+	for i := 0; i < 2; i++ {
+		_, err = rd.ReadString('\r')
+		require.Nil(t, err)
+	}
+
+	// the StatusReq is answered by the next Status Report, and a response with a ParsingError doesn't answer the CGF
+	_, err = device.Write([]byte("ST300CGF;Res;100850000;010;1;1\r" + st300Frame + cgfResponse))
+	require.Nil(t, err)
+	d := <-doneCh
+	assert.Equal(t, statusReq.ID, d.cmd.ID)
+	assert.Equal(t, st300Frame, string(d.resp.Frame))
+	d = <-doneCh
+	assert.Equal(t, cgf.ID, d.cmd.ID)
+	assert.Equal(t, cgfResponse, string(d.resp.Frame))
+}
+
+func TestQueueResend(t *testing.T) {
+	srv, q, doneCh := testQueue(NewMemoryStore())
+	_, err := q.Push([]byte(cgfCommand))
+	require.Nil(t, err)
+
+	device1, rd1 := connect(t, srv, st300Frame)
+	frame, err := rd1.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, cgfCommand+"\r", frame)
+	device1.Close()
+
+	// the command wasn't answered, so it's sent again on the next session
+	device2, rd2 := connect(t, srv, st300Frame)
+	defer device2.Close()
+	frame, err = rd2.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, cgfCommand+"\r", frame)
+	_, err = device2.Write([]byte(cgfResponse))
+	require.Nil(t, err)
+	<-doneCh
+
+	pending, err := q.Pending("100850000")
+	require.Nil(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestQueueRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	store, err := NewFileStore(path)
+	require.Nil(t, err)
+	srv, q, _ := testQueue(store)
+	_, err = q.Push([]byte(cgfCommand))
+	require.Nil(t, err)
+
+	device, rd := connect(t, srv, st300Frame)
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, cgfCommand+"\r", frame)
+	device.Close()
+	assert.Eventually(t, func() bool {
+		pending, err := q.Pending("100850000")
+		return err == nil && len(pending) == 1 && !pending[0].Sent.IsZero()
+	}, time.Second, 5*time.Millisecond)
+
+	// the response arrives after a restart
+	store, err = NewFileStore(path)
+	require.Nil(t, err)
+	srv, q, doneCh := testQueue(store)
+	device, _ = pipe(srv)
+	defer device.Close()
+	_, err = device.Write([]byte(cmdFrame + cgfResponse))
+	require.Nil(t, err)
+	d := <-doneCh
+	assert.Equal(t, cgfCommand, string(d.cmd.Frame))
+
+	pending, err := q.Pending("100850000")
+	require.Nil(t, err)
+	assert.Empty(t, pending)
+}
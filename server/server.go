@@ -0,0 +1,249 @@
+// Package server provides a gateway for Suntech devices: it accepts their TCP connections (or reads their UDP
+// datagrams), parses their frames and delivers the messages to a handler, keeping a session for each device.
+package server
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/larixsource/suntech"
+)
+
+var ErrServerClosed = errors.New("server closed")
+
+// Handler handles the messages of the devices. The messages of a session are handled one at a time, in the order of
+// their frames (including the ones with a ParsingError).
+type Handler interface {
+	Handle(s *Session, msg *suntech.Msg)
+}
+
+// HandlerFunc is an adapter to use a function as a Handler.
+type HandlerFunc func(s *Session, msg *suntech.Msg)
+
+func (f HandlerFunc) Handle(s *Session, msg *suntech.Msg) {
+	f(s, msg)
+}
+
+// Server is a TCP and UDP gateway. Its fields must be set before serving.
+type Server struct {
+	// Handler handles the messages of every session
+	Handler Handler
+
+	// ParserOpts are the options of the parser of each connection
+	ParserOpts suntech.ParserOpts
+
+	// IdleTimeout is the maximum time without reading from a connection (or without datagrams from a UDP device)
+	// before closing its session. Zero means no timeout.
+	IdleTimeout time.Duration
+
+	// WriteTimeout is the maximum time to write a frame to a device. Zero means no timeout.
+	WriteTimeout time.Duration
+
+	// AckEmergencies enables the acknowledgement of the Emergency Reports: the AckEmerg command is sent on each EMG
+	// frame, and the repeated frames of an emergency (until the device answers the command) aren't handled. The
+	// outcome is returned by Session.EmergencyAck.
+	AckEmergencies bool
+
+	// MaxDatagramSize is the size of the buffer to read the UDP datagrams, 65535 bytes when zero. A datagram that
+	// fills it is taken as truncated.
+	MaxDatagramSize int
+
+	mu      sync.Mutex
+	closed  bool
+	closers map[io.Closer]struct{}
+	conns   map[*Session]struct{}
+	devices map[string]*Session
+	wg      sync.WaitGroup
+
+	// emergencies holds the last emergency of each DevID (within emergencyRepeat), when AckEmergencies
+	emergencies map[string]*EmergencyAck
+}
+
+// Serve accepts the connections of a listener, serving each one in its own goroutine, until the listener fails or
+// the server is closed (returning ErrServerClosed).
+func (srv *Server) Serve(l net.Listener) error {
+	if !srv.trackCloser(l) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer srv.untrackCloser(l)
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if srv.isClosed() {
+				return ErrServerClosed
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+		go srv.ServeConn(conn)
+	}
+}
+
+// ListenAndServe listens on a TCP address and serves its connections.
+func (srv *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(l)
+}
+
+// ServeConn serves a connection until it's closed or a read error occurs (like the IdleTimeout), returning the
+// error (nil at the end of the stream).
+func (srv *Server) ServeConn(conn net.Conn) error {
+	s := &Session{
+		srv:  srv,
+		conn: conn,
+		done: make(chan struct{}),
+	}
+	if !srv.trackSession(s) {
+		conn.Close()
+		return ErrServerClosed
+	}
+	defer srv.untrackSession(s)
+	defer s.Close()
+
+	p := suntech.Parse(conn, srv.ParserOpts)
+	for {
+		if srv.IdleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(srv.IdleTimeout))
+		}
+		if !p.Next() {
+			break
+		}
+		msg := p.Msg()
+		if s.bind(msg) {
+			srv.bindSession(s)
+		}
+		srv.handle(s, msg)
+	}
+	return p.Error()
+}
+
+// handle delivers a message to the handler (after acknowledging it, if it's an Emergency Report).
+func (srv *Server) handle(s *Session, msg *suntech.Msg) {
+	if srv.AckEmergencies && !srv.ackEmergency(s, msg) {
+		return
+	}
+	if srv.Handler != nil {
+		srv.Handler.Handle(s, msg)
+	}
+}
+
+// Session returns the session bound to a DevID.
+func (srv *Server) Session(devID string) (*Session, bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	s, ok := srv.devices[devID]
+	return s, ok
+}
+
+// Sessions returns the sessions bound to a DevID.
+func (srv *Server) Sessions() []*Session {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	sessions := make([]*Session, 0, len(srv.devices))
+	for _, s := range srv.devices {
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// Close closes the listeners, the UDP sockets and the connections, waiting for the end of their sessions.
+func (srv *Server) Close() error {
+	srv.mu.Lock()
+	srv.closed = true
+	var err error
+	for c := range srv.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	for s := range srv.conns {
+		s.Close()
+	}
+	srv.mu.Unlock()
+
+	srv.wg.Wait()
+	return err
+}
+
+func (srv *Server) isClosed() bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.closed
+}
+
+// trackCloser tracks a listener or a UDP socket, to be closed with the server.
+func (srv *Server) trackCloser(c io.Closer) bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.closed {
+		return false
+	}
+	if srv.closers == nil {
+		srv.closers = make(map[io.Closer]struct{})
+	}
+	srv.closers[c] = struct{}{}
+	return true
+}
+
+func (srv *Server) untrackCloser(c io.Closer) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	delete(srv.closers, c)
+}
+
+func (srv *Server) trackSession(s *Session) bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.closed {
+		return false
+	}
+	if srv.conns == nil {
+		srv.conns = make(map[*Session]struct{})
+	}
+	srv.conns[s] = struct{}{}
+	srv.wg.Add(1)
+	return true
+}
+
+func (srv *Server) untrackSession(s *Session) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	delete(srv.conns, s)
+	srv.unbindSession(s)
+	srv.wg.Done()
+}
+
+// unbindSession removes the session of its DevID (unless the device has a newer one). srv.mu must be held.
+func (srv *Server) unbindSession(s *Session) {
+	if devID := s.DevID(); devID != "" && srv.devices[devID] == s {
+		delete(srv.devices, devID)
+	}
+}
+
+// bindSession binds a session to its DevID. The previous session of the device (a connection the device won't use
+// anymore, after reconnecting) is closed.
+func (srv *Server) bindSession(s *Session) {
+	srv.mu.Lock()
+	if srv.devices == nil {
+		srv.devices = make(map[string]*Session)
+	}
+	devID := s.DevID()
+	old := srv.devices[devID]
+	srv.devices[devID] = s
+	srv.mu.Unlock()
+
+	if old != nil && old != s {
+		old.Close()
+	}
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/larixsource/suntech"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	st300Frame = "ST300STT;100850000;01;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;001100;1;0072;0;4.5;1\r"
+	st600Frame = "ST600STT;200850000;20;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;00110000;1;0072;0;4.5;1;12.35\r"
+	alvFrame   = "ST600ALV;200850000\r"
+	cmdFrame   = "ST300CMD;Res;100850000;010;ReqIMSI;724031111553779\r"
+)
+
+type received struct {
+	session *Session
+	msg     *suntech.Msg
+}
+
+// testServer returns a server delivering its messages to a channel.
+func testServer() (*Server, chan received) {
+	ch := make(chan received, 16)
+	srv := &Server{
+		Handler: HandlerFunc(func(s *Session, msg *suntech.Msg) {
+			ch <- received{session: s, msg: msg}
+		}),
+	}
+	return srv, ch
+}
+
+func receive(t *testing.T, ch chan received) received {
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(time.Second):
+		require.FailNow(t, "no message received")
+		return received{}
+	}
+}
+
+// pipe serves one end of a pipe, returning the other one (the device) and the result of ServeConn.
+func pipe(srv *Server) (net.Conn, chan error) {
+	device, conn := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.ServeConn(conn)
+	}()
+	return device, done
+}
+
+func TestServeConn(t *testing.T) {
+	srv, ch := testServer()
+	device, done := pipe(srv)
+
+	_, err := device.Write([]byte(cmdFrame))
+	require.Nil(t, err)
+	r := receive(t, ch)
+	require.NotNil(t, r.msg.ST300)
+	assert.Equal(t, cmdFrame, string(r.msg.Frame))
+	assert.Equal(t, "", r.session.DevID())
+	_, ok := srv.Session("100850000")
+	assert.False(t, ok)
+
+	_, err = device.Write([]byte(st300Frame))
+	require.Nil(t, err)
+	r = receive(t, ch)
+	assert.Equal(t, st300Frame, string(r.msg.Frame))
+	assert.Equal(t, "100850000", r.session.DevID())
+	assert.Equal(t, suntech.ST300Family, r.session.Family())
+	s, ok := srv.Session("100850000")
+	require.True(t, ok)
+	assert.Same(t, r.session, s)
+	assert.Len(t, srv.Sessions(), 1)
+
+	// the session stays bound to the first DevID
+	_, err = device.Write([]byte(st600Frame))
+	require.Nil(t, err)
+	r = receive(t, ch)
+	assert.Equal(t, "100850000", r.session.DevID())
+	_, ok = srv.Session("200850000")
+	assert.False(t, ok)
+
+	require.Nil(t, device.Close())
+	assert.Nil(t, <-done)
+	_, ok = srv.Session("100850000")
+	assert.False(t, ok)
+}
+
+func TestSessionBindALV(t *testing.T) {
+	srv, ch := testServer()
+	device, done := pipe(srv)
+
+	_, err := device.Write([]byte(alvFrame))
+	require.Nil(t, err)
+	r := receive(t, ch)
+	assert.Equal(t, "200850000", r.session.DevID())
+	assert.Equal(t, suntech.ST600Family, r.session.Family())
+
+	device.Close()
+	<-done
+}
+
+func TestSessionSend(t *testing.T) {
+	srv, ch := testServer()
+	device, done := pipe(srv)
+
+	_, err := device.Write([]byte(st600Frame))
+	require.Nil(t, err)
+	r := receive(t, ch)
+
+	rd := bufio.NewReader(device)
+	go r.session.Send([]byte("ST600CMD;200850000;02;Reset"))
+	frame, err := rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, "ST600CMD;200850000;02;Reset\r", frame)
+
+	go r.session.Send([]byte("ST600CMD;200850000;02;StatusReq\r"))
+	frame, err = rd.ReadString('\r')
+	require.Nil(t, err)
+	assert.Equal(t, "ST600CMD;200850000;02;StatusReq\r", frame)
+
+	require.Nil(t, r.session.Close())
+	<-done
+	assert.Equal(t, ErrSessionClosed, r.session.Send([]byte("ST600CMD;200850000;02;Reset")))
+}
+
+func TestSessionReconnect(t *testing.T) {
+	srv, ch := testServer()
+	device1, done1 := pipe(srv)
+	_, err := device1.Write([]byte(st300Frame))
+	require.Nil(t, err)
+	first := receive(t, ch).session
+
+	device2, done2 := pipe(srv)
+	_, err = device2.Write([]byte(st300Frame))
+	require.Nil(t, err)
+	second := receive(t, ch).session
+	assert.NotSame(t, first, second)
+
+	// the first connection is closed, and the DevID stays bound to the second one
+	<-done1
+	s, ok := srv.Session("100850000")
+	require.True(t, ok)
+	assert.Same(t, second, s)
+
+	device2.Close()
+	<-done2
+	_, ok = srv.Session("100850000")
+	assert.False(t, ok)
+}
+
+func TestIdleTimeout(t *testing.T) {
+	srv, _ := testServer()
+	srv.IdleTimeout = 10 * time.Millisecond
+	device, done := pipe(srv)
+	defer device.Close()
+
+	select {
+	case err := <-done:
+		var ne net.Error
+		require.ErrorAs(t, err, &ne)
+		assert.True(t, ne.Timeout())
+	case <-time.After(time.Second):
+		assert.Fail(t, "the idle connection wasn't closed")
+	}
+}
+
+func TestServe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	srv, ch := testServer()
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(l)
+	}()
+
+	device, err := net.Dial("tcp", l.Addr().String())
+	require.Nil(t, err)
+	defer device.Close()
+	_, err = device.Write([]byte(st600Frame + alvFrame))
+	require.Nil(t, err)
+
+	r := receive(t, ch)
+	assert.Equal(t, st600Frame, string(r.msg.Frame))
+	assert.Equal(t, "200850000", r.session.DevID())
+	r = receive(t, ch)
+	assert.Equal(t, alvFrame, string(r.msg.Frame))
+
+	require.Nil(t, srv.Close())
+	assert.Equal(t, ErrServerClosed, <-served)
+	assert.Empty(t, srv.Sessions())
+	assert.Equal(t, ErrServerClosed, srv.Serve(l))
+}
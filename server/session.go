@@ -0,0 +1,130 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/larixsource/suntech"
+	"github.com/larixsource/suntech/st"
+)
+
+var ErrSessionClosed = errors.New("session closed")
+
+// conn is the transport of a session: a TCP connection, or the address of a device in a UDP socket.
+type conn interface {
+	Write(b []byte) (int, error)
+	SetWriteDeadline(t time.Time) error
+	RemoteAddr() net.Addr
+	Close() error
+}
+
+// Session is the connection of a device. It's bound to the DevID of the device when its first report arrives.
+type Session struct {
+	srv  *Server
+	conn conn
+
+	mu     sync.Mutex
+	devID  string
+	family suntech.Family
+	closed bool
+	done   chan struct{}
+
+	// wmu serializes the writes to the connection
+	wmu sync.Mutex
+}
+
+// DevID returns the DevID of the device, or "" when no report has arrived yet.
+func (s *Session) DevID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.devID
+}
+
+// Family returns the family of models of the device, or UnknownFamily when no report has arrived yet.
+func (s *Session) Family() suntech.Family {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.family
+}
+
+// Done returns a channel closed at the end of the session.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// RemoteAddr returns the address of the device (the source of its last datagram, for UDP).
+func (s *Session) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+// Send writes a frame to the device, like the ones built by the Command method of the commands. The ending CR is
+// added when missing.
+func (s *Session) Send(frame []byte) error {
+	if len(frame) == 0 || frame[len(frame)-1] != st.EndOfFrame {
+		frame = append(frame[:len(frame):len(frame)], st.EndOfFrame)
+	}
+
+	if s.isClosed() {
+		return ErrSessionClosed
+	}
+
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	if s.srv.WriteTimeout > 0 {
+		s.conn.SetWriteDeadline(time.Now().Add(s.srv.WriteTimeout))
+	}
+	_, err := s.conn.Write(frame)
+	return err
+}
+
+// Close closes the connection of the device, ending its session. The session of a UDP device is forgotten (the next
+// datagram of the device starts a new one).
+func (s *Session) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+	return s.conn.Close()
+}
+
+func (s *Session) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// bind binds the session to the DevID of a message, if it's the first report of the device. It returns true when
+// the session was bound by this message.
+func (s *Session) bind(msg *suntech.Msg) bool {
+	devID := MsgDevID(msg)
+	if devID == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.devID != "" {
+		return false
+	}
+	s.devID = devID
+	s.family = msg.Family
+	return true
+}
+
+// MsgDevID returns the DevID of a report (including the Alive Report of the ST600 family), or "" for the other
+// messages.
+func MsgDevID(msg *suntech.Msg) string {
+	if r := msg.Report(); r != nil {
+		return r.Common().DevID
+	}
+	if msg.ST600 != nil && msg.ST600.ALV != nil {
+		return msg.ST600.ALV.DevID
+	}
+	return ""
+}
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var ErrUnknownCommand = errors.New("unknown queued command")
+
+// QueuedCommand is a command waiting in the queue of its device, until its response arrives.
+type QueuedCommand struct {
+	// ID is assigned by the Store, in the order of the commands
+	ID    uint64
+	DevID string
+	Frame []byte
+
+	Queued time.Time
+
+	// Sent is the last time the command was sent to the device (zero when it hasn't been sent yet)
+	Sent time.Time
+}
+
+// Store keeps the queued commands of the devices.
+type Store interface {
+	// Push adds a command to the queue of its device, assigning its ID.
+	Push(cmd *QueuedCommand) error
+
+	// Commands returns the queued commands of a device, in order.
+	Commands(devID string) ([]*QueuedCommand, error)
+
+	// Update saves the changes of a queued command (like the Sent time).
+	Update(cmd *QueuedCommand) error
+
+	// Remove removes a command from its queue.
+	Remove(id uint64) error
+}
+
+// MemoryStore is a Store that keeps the commands in memory, lost when the process ends.
+type MemoryStore struct {
+	mu       sync.Mutex
+	lastID   uint64
+	commands []*QueuedCommand
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (m *MemoryStore) Push(cmd *QueuedCommand) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID++
+	cmd.ID = m.lastID
+	c := *cmd
+	m.commands = append(m.commands, &c)
+	return nil
+}
+
+func (m *MemoryStore) Commands(devID string) ([]*QueuedCommand, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var commands []*QueuedCommand
+	for _, cmd := range m.commands {
+		if cmd.DevID == devID {
+			c := *cmd
+			commands = append(commands, &c)
+		}
+	}
+	return commands, nil
+}
+
+func (m *MemoryStore) Update(cmd *QueuedCommand) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.commands {
+		if c.ID == cmd.ID {
+			*c = *cmd
+			return nil
+		}
+	}
+	return ErrUnknownCommand
+}
+
+func (m *MemoryStore) Remove(id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, c := range m.commands {
+		if c.ID == id {
+			m.commands = append(m.commands[:i], m.commands[i+1:]...)
+			return nil
+		}
+	}
+	return ErrUnknownCommand
+}
+
+// snapshot returns a copy of the commands, to restore them after a failed change.
+func (m *MemoryStore) snapshot() (lastID uint64, commands []*QueuedCommand) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	commands = make([]*QueuedCommand, 0, len(m.commands))
+	for _, cmd := range m.commands {
+		c := *cmd
+		commands = append(commands, &c)
+	}
+	return m.lastID, commands
+}
+
+func (m *MemoryStore) restore(lastID uint64, commands []*QueuedCommand) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID = lastID
+	m.commands = commands
+}
+
+// FileStore is a Store that keeps the commands in a JSON file, rewritten (and synced) on each change, so the queues
+// survive a restart of the process or a crash.
+type FileStore struct {
+	path string
+	mem  MemoryStore
+
+	// mu serializes the changes, so the file is saved in the same order, and the commands are read as saved
+	mu sync.Mutex
+}
+
+// fileQueue is the content of the file of a FileStore.
+type fileQueue struct {
+	LastID   uint64
+	Commands []fileCommand
+}
+
+type fileCommand struct {
+	ID     uint64
+	DevID  string
+	Frame  string
+	Queued time.Time
+	Sent   time.Time
+}
+
+// NewFileStore returns a FileStore saving the commands in a file, loading the ones saved before (if the file
+// exists).
+func NewFileStore(path string) (*FileStore, error) {
+	fs := &FileStore{
+		path: path,
+	}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var q fileQueue
+	if err := json.Unmarshal(b, &q); err != nil {
+		return nil, err
+	}
+	fs.mem.lastID = q.LastID
+	for _, c := range q.Commands {
+		fs.mem.commands = append(fs.mem.commands, &QueuedCommand{
+			ID:     c.ID,
+			DevID:  c.DevID,
+			Frame:  []byte(c.Frame),
+			Queued: c.Queued,
+			Sent:   c.Sent,
+		})
+	}
+	return fs, nil
+}
+
+func (fs *FileStore) Push(cmd *QueuedCommand) error {
+	return fs.change(func() error {
+		return fs.mem.Push(cmd)
+	})
+}
+
+func (fs *FileStore) Commands(devID string) ([]*QueuedCommand, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.mem.Commands(devID)
+}
+
+func (fs *FileStore) Update(cmd *QueuedCommand) error {
+	return fs.change(func() error {
+		return fs.mem.Update(cmd)
+	})
+}
+
+func (fs *FileStore) Remove(id uint64) error {
+	return fs.change(func() error {
+		return fs.mem.Remove(id)
+	})
+}
+
+// change applies a change to the commands and saves them. The change is undone when the file can't be saved, so the
+// commands stay as saved.
+func (fs *FileStore) change(apply func() error) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	lastID, commands := fs.mem.snapshot()
+	if err := apply(); err != nil {
+		return err
+	}
+	if err := fs.save(); err != nil {
+		fs.mem.restore(lastID, commands)
+		return err
+	}
+	return nil
+}
+
+// save writes the commands to a temporary file, renamed to the path of the store, so a failed write doesn't lose
+// the previous content. The file and its directory are synced before returning, so the change survives a crash of
+// the system.
+func (fs *FileStore) save() error {
+	fs.mem.mu.Lock()
+	q := fileQueue{
+		LastID:   fs.mem.lastID,
+		Commands: make([]fileCommand, 0, len(fs.mem.commands)),
+	}
+	for _, c := range fs.mem.commands {
+		q.Commands = append(q.Commands, fileCommand{
+			ID:     c.ID,
+			DevID:  c.DevID,
+			Frame:  string(c.Frame),
+			Queued: c.Queued,
+			Sent:   c.Sent,
+		})
+	}
+	fs.mem.mu.Unlock()
+
+	b, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(fs.path), filepath.Base(fs.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), fs.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return syncDir(filepath.Dir(fs.path))
+}
+
+// syncDir syncs a directory, to persist the rename of a file.
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	err = dir.Sync()
+	if cerr := dir.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
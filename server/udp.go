@@ -0,0 +1,258 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/larixsource/suntech"
+	"github.com/larixsource/suntech/st"
+)
+
+// ErrTruncatedDatagram is the ParsingError of the last message of a datagram that doesn't end with a complete frame
+// (or that fills the buffer of MaxDatagramSize bytes).
+var ErrTruncatedDatagram = errors.New("truncated datagram")
+
+// defaultDatagramSize is the maximum size of a UDP datagram.
+const defaultDatagramSize = 65535
+
+// ServePacket reads the datagrams of a UDP socket, until it fails or the server is closed (returning
+// ErrServerClosed). Each datagram holds one or more frames, parsed on their own.
+//
+// The sessions of the socket are bound to the DevID of the devices, like the ones of the TCP connections, sending
+// the frames to the source address of the last datagram of each device. The datagrams without a DevID (like the
+// responses of the commands) belong to the session of their source address. A session without datagrams for the
+// IdleTimeout is forgotten.
+func (srv *Server) ServePacket(pc net.PacketConn) error {
+	if !srv.trackCloser(pc) {
+		pc.Close()
+		return ErrServerClosed
+	}
+	srv.mu.Lock()
+	srv.wg.Add(1)
+	srv.mu.Unlock()
+	defer srv.wg.Done()
+	defer srv.untrackCloser(pc)
+
+	ps := &packetSessions{
+		srv:      srv,
+		pc:       pc,
+		peers:    make(map[string]*Session),
+		sessions: make(map[string]*Session),
+	}
+	defer ps.closeAll()
+
+	size := srv.MaxDatagramSize
+	if size <= 0 {
+		size = defaultDatagramSize
+	}
+	buf := make([]byte, size)
+	lastExpire := time.Now()
+	for {
+		if srv.IdleTimeout > 0 {
+			pc.SetReadDeadline(time.Now().Add(srv.IdleTimeout))
+		}
+		n, addr, err := pc.ReadFrom(buf)
+		if n > 0 {
+			ps.handle(append([]byte(nil), buf[:n]...), addr, n == len(buf))
+		}
+		if srv.IdleTimeout > 0 && time.Since(lastExpire) >= srv.IdleTimeout {
+			ps.expire(time.Now().Add(-srv.IdleTimeout))
+			lastExpire = time.Now()
+		}
+		if err != nil {
+			if srv.isClosed() {
+				return ErrServerClosed
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+	}
+}
+
+// ListenAndServePacket listens on a UDP address and serves its datagrams.
+func (srv *Server) ListenAndServePacket(addr string) error {
+	pc, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	return srv.ServePacket(pc)
+}
+
+// parseDatagram parses the frames of a datagram. When it's truncated, the error of its last message is wrapped in
+// ErrTruncatedDatagram, or a message with that error is added when the last frame looks complete.
+func parseDatagram(datagram []byte, full bool, opts suntech.ParserOpts) []*suntech.Msg {
+	var msgs []*suntech.Msg
+	p := suntech.ParseBytes(datagram, opts)
+	for p.Next() {
+		msgs = append(msgs, p.Msg())
+	}
+
+	last := datagram[len(datagram)-1]
+	if !full && (last == st.EndOfFrame || last == st.ETX) {
+		return msgs
+	}
+	if len(msgs) > 0 && msgs[len(msgs)-1].ParsingError != nil {
+		msg := msgs[len(msgs)-1]
+		msg.ParsingError = fmt.Errorf("%w: %v", ErrTruncatedDatagram, msg.ParsingError)
+	} else {
+		msgs = append(msgs, &suntech.Msg{ParsingError: ErrTruncatedDatagram})
+	}
+	return msgs
+}
+
+// packetSessions are the sessions of a UDP socket, by DevID and by source address.
+type packetSessions struct {
+	srv *Server
+	pc  net.PacketConn
+
+	mu       sync.Mutex
+	peers    map[string]*Session
+	sessions map[string]*Session
+}
+
+func (ps *packetSessions) handle(datagram []byte, addr net.Addr, full bool) {
+	for _, msg := range parseDatagram(datagram, full, ps.srv.ParserOpts) {
+		s := ps.session(msg, addr)
+		ps.srv.handle(s, msg)
+	}
+}
+
+// session returns the session of a message from addr: the one of its DevID, or else the one of addr (a new one when
+// addr has a session of another device).
+func (ps *packetSessions) session(msg *suntech.Msg, addr net.Addr) *Session {
+	devID := MsgDevID(msg)
+	key := addr.String()
+
+	ps.mu.Lock()
+	s := ps.sessions[devID]
+	if s == nil {
+		s = ps.peers[key]
+		if s == nil || (devID != "" && s.DevID() != "" && s.DevID() != devID) {
+			s = ps.newSession(addr)
+		}
+	}
+	s.conn.(*packetConn).seen(addr)
+	ps.peers[key] = s
+	bound := s.bind(msg)
+	if bound {
+		ps.sessions[devID] = s
+	}
+	ps.mu.Unlock()
+
+	if bound {
+		ps.srv.bindSession(s)
+	}
+	return s
+}
+
+// newSession returns a session for addr. ps.mu must be held.
+func (ps *packetSessions) newSession(addr net.Addr) *Session {
+	s := &Session{
+		srv:  ps.srv,
+		done: make(chan struct{}),
+	}
+	s.conn = &packetConn{
+		ps:      ps,
+		session: s,
+		addr:    addr,
+	}
+	return s
+}
+
+// remove forgets a session.
+func (ps *packetSessions) remove(s *Session) {
+	ps.mu.Lock()
+	for key, peer := range ps.peers {
+		if peer == s {
+			delete(ps.peers, key)
+		}
+	}
+	if devID := s.DevID(); devID != "" && ps.sessions[devID] == s {
+		delete(ps.sessions, devID)
+	}
+	ps.mu.Unlock()
+
+	ps.srv.mu.Lock()
+	ps.srv.unbindSession(s)
+	ps.srv.mu.Unlock()
+}
+
+// expire closes the sessions without datagrams since a time.
+func (ps *packetSessions) expire(since time.Time) {
+	for _, s := range ps.all() {
+		if s.conn.(*packetConn).lastSeen().Before(since) {
+			s.Close()
+		}
+	}
+}
+
+func (ps *packetSessions) closeAll() {
+	for _, s := range ps.all() {
+		s.Close()
+	}
+}
+
+func (ps *packetSessions) all() []*Session {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	seen := make(map[*Session]struct{})
+	var all []*Session
+	for _, sessions := range []map[string]*Session{ps.peers, ps.sessions} {
+		for _, s := range sessions {
+			if _, ok := seen[s]; !ok {
+				seen[s] = struct{}{}
+				all = append(all, s)
+			}
+		}
+	}
+	return all
+}
+
+// packetConn is the transport of the session of a UDP device, writing to the source address of its last datagram.
+type packetConn struct {
+	ps      *packetSessions
+	session *Session
+
+	mu   sync.Mutex
+	addr net.Addr
+	last time.Time
+}
+
+func (c *packetConn) seen(addr net.Addr) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addr = addr
+	c.last = time.Now()
+}
+
+func (c *packetConn) lastSeen() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+func (c *packetConn) Write(b []byte) (int, error) {
+	return c.ps.pc.WriteTo(b, c.RemoteAddr())
+}
+
+// SetWriteDeadline does nothing, the socket is shared by every session.
+func (c *packetConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *packetConn) RemoteAddr() net.Addr {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.addr
+}
+
+func (c *packetConn) Close() error {
+	c.ps.remove(c.session)
+	return nil
+}
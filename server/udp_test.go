@@ -0,0 +1,174 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/larixsource/suntech"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDatagram(t *testing.T) {
+	msgs := parseDatagram([]byte(st300Frame+st600Frame), false, suntech.ParserOpts{})
+	require.Len(t, msgs, 2)
+	assert.Nil(t, msgs[0].ParsingError)
+	assert.Nil(t, msgs[1].ParsingError)
+
+	// the last frame is cut
+	msgs = parseDatagram([]byte(st300Frame+st600Frame[:40]), false, suntech.ParserOpts{})
+	require.Len(t, msgs, 2)
+	assert.Nil(t, msgs[0].ParsingError)
+	assert.True(t, errors.Is(msgs[1].ParsingError, ErrTruncatedDatagram))
+	assert.EqualError(t, msgs[1].ParsingError, "truncated datagram: EOF")
+	assert.Equal(t, st600Frame[:40], string(msgs[1].Frame))
+
+	// cut in the header of the last frame
+	msgs = parseDatagram([]byte(st300Frame+"ST60"), false, suntech.ParserOpts{})
+	require.Len(t, msgs, 2)
+	assert.True(t, errors.Is(msgs[1].ParsingError, ErrTruncatedDatagram))
+	assert.Equal(t, "ST60", string(msgs[1].Frame))
+
+	// the datagram fills the buffer, so the frames after it are lost
+	msgs = parseDatagram([]byte(st300Frame), true, suntech.ParserOpts{})
+	require.Len(t, msgs, 2)
+	assert.Nil(t, msgs[0].ParsingError)
+	assert.True(t, errors.Is(msgs[1].ParsingError, ErrTruncatedDatagram))
+	assert.Empty(t, msgs[1].Frame)
+}
+
+// udpServer serves a loopback UDP socket, returning its address.
+func udpServer(t *testing.T, srv *Server) (net.Addr, chan error) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.ServePacket(pc)
+	}()
+	return pc.LocalAddr(), served
+}
+
+func udpDevice(t *testing.T, addr net.Addr) net.Conn {
+	device, err := net.Dial("udp", addr.String())
+	require.Nil(t, err)
+	return device
+}
+
+func TestServePacket(t *testing.T) {
+	srv, ch := testServer()
+	addr, served := udpServer(t, srv)
+
+	device := udpDevice(t, addr)
+	defer device.Close()
+	_, err := device.Write([]byte(st300Frame + cmdFrame))
+	require.Nil(t, err)
+
+	r := receive(t, ch)
+	assert.Equal(t, st300Frame, string(r.msg.Frame))
+	assert.Equal(t, "100850000", r.session.DevID())
+	assert.Equal(t, suntech.ST300Family, r.session.Family())
+	assert.Equal(t, device.LocalAddr().String(), r.session.RemoteAddr().String())
+	session := r.session
+
+	// the response (without a DevID) belongs to the session of its address
+	r = receive(t, ch)
+	assert.Equal(t, cmdFrame, string(r.msg.Frame))
+	assert.Same(t, session, r.session)
+
+	s, ok := srv.Session("100850000")
+	require.True(t, ok)
+	assert.Same(t, session, s)
+
+	// commands are sent back to the address of the device
+	require.Nil(t, s.Send([]byte("ST300CMD;100850000;02;Reset")))
+	buf := make([]byte, 128)
+	device.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := device.Read(buf)
+	require.Nil(t, err)
+	assert.Equal(t, "ST300CMD;100850000;02;Reset\r", string(buf[:n]))
+
+	require.Nil(t, srv.Close())
+	assert.Equal(t, ErrServerClosed, <-served)
+	assert.Empty(t, srv.Sessions())
+	assert.Equal(t, ErrSessionClosed, s.Send([]byte("ST300CMD;100850000;02;Reset")))
+}
+
+func TestServePacketNewAddr(t *testing.T) {
+	srv, ch := testServer()
+	addr, served := udpServer(t, srv)
+
+	device1 := udpDevice(t, addr)
+	defer device1.Close()
+	_, err := device1.Write([]byte(st600Frame))
+	require.Nil(t, err)
+	first := receive(t, ch).session
+
+	// the device reports from another address (like after a new NAT mapping)
+	device2 := udpDevice(t, addr)
+	defer device2.Close()
+	_, err = device2.Write([]byte(alvFrame))
+	require.Nil(t, err)
+	r := receive(t, ch)
+	assert.Same(t, first, r.session)
+	assert.Equal(t, device2.LocalAddr().String(), r.session.RemoteAddr().String())
+
+	srv.Close()
+	<-served
+}
+
+func TestServePacketTruncated(t *testing.T) {
+	srv, ch := testServer()
+	srv.MaxDatagramSize = len(st300Frame)
+	addr, served := udpServer(t, srv)
+
+	device := udpDevice(t, addr)
+	defer device.Close()
+	_, err := device.Write([]byte(st300Frame + alvFrame))
+	require.Nil(t, err)
+
+	r := receive(t, ch)
+	assert.Nil(t, r.msg.ParsingError)
+	assert.Equal(t, st300Frame, string(r.msg.Frame))
+	r = receive(t, ch)
+	assert.True(t, errors.Is(r.msg.ParsingError, ErrTruncatedDatagram))
+	assert.Equal(t, "100850000", r.session.DevID())
+
+	_, err = device.Write([]byte(st600Frame[:20]))
+	require.Nil(t, err)
+	r = receive(t, ch)
+	assert.True(t, errors.Is(r.msg.ParsingError, ErrTruncatedDatagram))
+	assert.Equal(t, st600Frame[:20], string(r.msg.Frame))
+
+	srv.Close()
+	<-served
+}
+
+func TestServePacketIdle(t *testing.T) {
+	srv, ch := testServer()
+	srv.IdleTimeout = 20 * time.Millisecond
+	addr, served := udpServer(t, srv)
+
+	device := udpDevice(t, addr)
+	defer device.Close()
+	_, err := device.Write([]byte(st600Frame))
+	require.Nil(t, err)
+	first := receive(t, ch).session
+
+	assert.Eventually(t, func() bool {
+		_, ok := srv.Session("200850000")
+		return !ok
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, ErrSessionClosed, first.Send([]byte("ST600CMD;200850000;02;Reset")))
+
+	// the next datagram starts a new session
+	_, err = device.Write([]byte(alvFrame))
+	require.Nil(t, err)
+	r := receive(t, ch)
+	assert.NotSame(t, first, r.session)
+	assert.Equal(t, "200850000", r.session.DevID())
+
+	srv.Close()
+	<-served
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/larixsource/suntech/lexer"
@@ -42,6 +43,7 @@ var (
 	ErrInvalidEvtID     = errors.New("invalid EvtID")
 	ErrInvalidAltID     = errors.New("invalid AltID")
 	ErrInvalidHLen      = errors.New("invalid Length")
+	ErrInvalidChecksum  = errors.New("invalid Checksum")
 )
 
 func AsciiDevID(lex *lexer.Lexer) (devID string, token lexer.Token, err error) {
@@ -492,7 +494,16 @@ func AsciiEmgID(lex *lexer.Lexer) (emgType EmergencyType, token lexer.Token, err
 		err = ErrSeparator
 		return
 	}
-	switch token.Literal[0] {
+	var ok bool
+	if emgType, ok = emergencyTypeOf(token.Literal[0]); !ok {
+		err = fmt.Errorf("unknown EmgID value: %v", token.Literal[0])
+	}
+	return
+}
+
+// emergencyTypeOf returns the EmergencyType of the given EmgID char.
+func emergencyTypeOf(c byte) (emgType EmergencyType, ok bool) {
+	switch c {
 	case '1':
 		emgType = PanicButtonEmg
 	case '2':
@@ -508,8 +519,9 @@ func AsciiEmgID(lex *lexer.Lexer) (emgType EmergencyType, token lexer.Token, err
 	case '8':
 		emgType = AntiTheftShockEmg
 	default:
-		err = fmt.Errorf("unknown EmgID value: %v", token.Literal[0])
+		return
 	}
+	ok = true
 	return
 }
 
@@ -526,7 +538,16 @@ func AsciiEvtID(lex *lexer.Lexer) (evtType EventType, token lexer.Token, err err
 		err = ErrSeparator
 		return
 	}
-	switch token.Literal[0] {
+	var ok bool
+	if evtType, ok = eventTypeOf(token.Literal[0]); !ok {
+		err = fmt.Errorf("unknown EvtID value: %v", token.Literal[0])
+	}
+	return
+}
+
+// eventTypeOf returns the EventType of the given EvtID char.
+func eventTypeOf(c byte) (evtType EventType, ok bool) {
+	switch c {
 	case '1':
 		evtType = Input1GroundEvt
 	case '2':
@@ -540,8 +561,9 @@ func AsciiEvtID(lex *lexer.Lexer) (evtType EventType, token lexer.Token, err err
 	case '6':
 		evtType = Input3OpenEvt
 	default:
-		err = fmt.Errorf("unknown EvtID value: %v", token.Literal[0])
+		return
 	}
+	ok = true
 	return
 }
 
@@ -558,7 +580,58 @@ func AsciiAltID(lex *lexer.Lexer) (altType AlertType, token lexer.Token, err err
 		err = ErrSeparator
 		return
 	}
-	switch string(token.WithoutSuffix()) {
+	var ok bool
+	if altType, ok = alertTypeOf(string(token.WithoutSuffix())); !ok {
+		err = fmt.Errorf("unknown AltID value: %v", token.Literal[0])
+	}
+	return
+}
+
+// AsciiGeoAltID reads an AltID that could be followed by the group of a geofence, like "6002" (entered the circular
+// geofence group 2) or "6P02" (entered the polygonal geofence group 2). The fence is NoFence for a plain AltID.
+func AsciiGeoAltID(lex *lexer.Lexer) (altType AlertType, fence FenceType, groupID int, token lexer.Token, err error) {
+	token, err = lex.Next(7, Separator)
+	if err != nil {
+		return
+	}
+	if !token.EndsWith(Separator) {
+		err = ErrSeparator
+		return
+	}
+	code := string(token.WithoutSuffix())
+	var group string
+	switch {
+	case strings.IndexByte(code, 'P') >= 0:
+		i := strings.IndexByte(code, 'P')
+		code, group = code[:i], code[i+1:]
+		fence = PolygonFence
+	case len(code) == 4:
+		code, group = code[:1], code[1:]
+		fence = CircleFence
+	}
+	if !isDigits(code) || (fence != NoFence && !isDigits(group)) {
+		err = ErrInvalidAltID
+		return
+	}
+	var ok bool
+	if altType, ok = alertTypeOf(code); !ok {
+		err = fmt.Errorf("unknown AltID value: %s", code)
+		return
+	}
+	if fence != NoFence {
+		groupID, err = strconv.Atoi(group)
+	}
+	return
+}
+
+// isDigits tells if s is a not empty string of digits.
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// alertTypeOf returns the AlertType of the given AltID code.
+func alertTypeOf(code string) (altType AlertType, ok bool) {
+	switch code {
 	case "1":
 		altType = StartOverSpeedAlt
 	case "2":
@@ -650,8 +723,9 @@ func AsciiAltID(lex *lexer.Lexer) (altType AlertType, token lexer.Token, err err
 	case "73":
 		altType = RapidFuelReductionAlt
 	default:
-		err = fmt.Errorf("unknown AltID value: %v", token.Literal[0])
+		return
 	}
+	ok = true
 	return
 }
 
@@ -702,8 +776,8 @@ func AsciiChecksum(lex *lexer.Lexer) (chk uint8, token lexer.Token, err error) {
 	if err != nil {
 		return
 	}
-	if !token.OnlyDigits() {
-		err = ErrInvalidHMeter
+	if !token.IsHex() {
+		err = ErrInvalidChecksum
 		return
 	}
 	crc, parseErr := strconv.ParseUint(string(token.WithoutSuffix()), 16, 16)
@@ -0,0 +1,310 @@
+package st
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AsciiWriter builds an ascii frame, field by field, using the same field widths read by the Ascii* functions.
+// The first error found is kept, and the following writes are ignored.
+type AsciiWriter struct {
+	buf bytes.Buffer
+	err error
+}
+
+// Err returns the first error found while writing the fields.
+func (w *AsciiWriter) Err() error {
+	return w.err
+}
+
+// Frame returns the complete frame, ending with a CR.
+func (w *AsciiWriter) Frame() ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	frame := make([]byte, 0, w.buf.Len()+1)
+	frame = append(frame, w.buf.Bytes()...)
+	frame = append(frame, EndOfFrame)
+	return frame, nil
+}
+
+// Command returns the frame without the ending CR, as the configuration commands are built.
+func (w *AsciiWriter) Command() ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	return append([]byte(nil), w.buf.Bytes()...), nil
+}
+
+func (w *AsciiWriter) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// Hdr writes the header of the frame, like "ST300STT". It must be the first write.
+func (w *AsciiWriter) Hdr(hdr string) {
+	if w.err != nil {
+		return
+	}
+	w.buf.WriteString(hdr)
+}
+
+// Field writes a field as is, preceded by a separator.
+func (w *AsciiWriter) Field(field string) {
+	if w.err != nil {
+		return
+	}
+	w.buf.WriteByte(Separator)
+	w.buf.WriteString(field)
+}
+
+// digits writes v as a decimal number, zero padded to width digits, failing if it's longer than maxLen digits.
+func (w *AsciiWriter) digits(v uint64, width, maxLen int, invalid error) {
+	sz := fmt.Sprintf("%0*d", width, v)
+	if len(sz) > maxLen {
+		w.fail(invalid)
+		return
+	}
+	w.Field(sz)
+}
+
+// float writes v with prec decimals, the integer part zero padded to width digits (and no longer than maxInt
+// digits). If signed, the sign is always written.
+func (w *AsciiWriter) float(v float32, width, maxInt, prec int, signed bool, invalid error) {
+	sz := strconv.FormatFloat(float64(v), 'f', prec, 32)
+	sign := "+"
+	if strings.HasPrefix(sz, "-") {
+		sign = "-"
+		sz = sz[1:]
+	}
+	if sign == "-" && !signed {
+		w.fail(invalid)
+		return
+	}
+	intLen := len(sz)
+	if prec > 0 {
+		intLen = strings.IndexByte(sz, '.')
+	}
+	if intLen > maxInt {
+		w.fail(invalid)
+		return
+	}
+	if intLen < width {
+		sz = strings.Repeat("0", width-intLen) + sz
+	}
+	if signed {
+		sz = sign + sz
+	}
+	w.Field(sz)
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// DevID writes a DevID of 9 digits.
+func (w *AsciiWriter) DevID(devID string) {
+	if len(devID) != 9 || strings.Trim(devID, "0123456789") != "" {
+		w.fail(ErrInvalidDevID)
+		return
+	}
+	w.Field(devID)
+}
+
+func (w *AsciiWriter) Model(model Model) {
+	w.digits(uint64(model), 2, 2, ErrInvalidModel)
+}
+
+func (w *AsciiWriter) SwVer(swVer uint16) {
+	w.digits(uint64(swVer), 3, 3, ErrInvalidSwVer)
+}
+
+// Timestamp writes the date and time fields, in UTC.
+func (w *AsciiWriter) Timestamp(ts time.Time) {
+	ts = ts.UTC()
+	if ts.Year() < 1000 || ts.Year() > 9999 {
+		w.fail(ErrInvalidDate)
+		return
+	}
+	w.Field(ts.Format("20060102"))
+	w.Field(ts.Format("15:04:05"))
+}
+
+// Cell writes a cell given as an hex string, of up to 6 digits (as read by AsciiCell).
+func (w *AsciiWriter) Cell(cell string) {
+	w.Hex(cell, 6, ErrInvalidCell)
+}
+
+// Hex writes a field given as an hex string of up to maxLen digits, failing with invalid otherwise.
+func (w *AsciiWriter) Hex(field string, maxLen int, invalid error) {
+	if len(field) > maxLen || !isHex(field) {
+		w.fail(invalid)
+		return
+	}
+	w.Field(field)
+}
+
+func (w *AsciiWriter) Lat(lat float32) {
+	w.float(lat, 2, 2, 6, true, ErrInvalidLat)
+}
+
+func (w *AsciiWriter) Lon(lon float32) {
+	w.float(lon, 3, 3, 6, true, ErrInvalidLng)
+}
+
+// FormatCoord formats a latitude or longitude with its sign and 6 decimals (like "+22.500000"), as sent in the
+// commands that carry coordinates (like the route and polygon geofences).
+func FormatCoord(v float32) string {
+	s := strconv.FormatFloat(float64(v), 'f', 6, 32)
+	if !strings.HasPrefix(s, "-") {
+		s = "+" + s
+	}
+	return s
+}
+
+func (w *AsciiWriter) Speed(speed float32) {
+	w.float(speed, 3, 3, 3, false, ErrInvalidSpeed)
+}
+
+func (w *AsciiWriter) Course(course float32) {
+	w.float(course, 3, 3, 2, false, ErrInvalidCourse)
+}
+
+func (w *AsciiWriter) Satellites(satellites uint8) {
+	w.digits(uint64(satellites), 1, 2, ErrInvalidSatt)
+}
+
+func (w *AsciiWriter) Fix(fix bool) {
+	w.Bit(fix)
+}
+
+func (w *AsciiWriter) Distance(distance uint32) {
+	w.digits(uint64(distance), 1, 10, ErrInvalidDist)
+}
+
+func (w *AsciiWriter) PowerVolt(powerVolt float32) {
+	w.float(powerVolt, 1, 7, 2, false, ErrInvalidPowerVolt)
+}
+
+// IO writes the IO status, a string of '0' and '1' chars.
+func (w *AsciiWriter) IO(ioStatus string) {
+	if ioStatus == "" || len(ioStatus) > 8 || strings.Trim(ioStatus, "01") != "" {
+		w.fail(ErrInvalidIO)
+		return
+	}
+	w.Field(ioStatus)
+}
+
+func (w *AsciiWriter) Mode(mode ModeType) {
+	switch mode {
+	case ParkingMode, DrivingMode, DistanceMode, AngleMode:
+		w.Field(strconv.Itoa(int(mode)))
+	default:
+		w.fail(ErrInvalidMode)
+	}
+}
+
+func (w *AsciiWriter) MsgNum(msgNum uint16) {
+	w.digits(uint64(msgNum), 4, 4, ErrInvalidMsgNum)
+}
+
+func (w *AsciiWriter) DrivingHourMeter(hmeter uint32) {
+	w.digits(uint64(hmeter), 6, 7, ErrInvalidHMeter)
+}
+
+func (w *AsciiWriter) BackupVolt(backupVolt float32) {
+	w.float(backupVolt, 1, 8, 1, false, ErrInvalidPowerVolt)
+}
+
+func (w *AsciiWriter) Bit(value bool) {
+	if value {
+		w.Field("1")
+	} else {
+		w.Field("0")
+	}
+}
+
+func (w *AsciiWriter) EmgID(emgType EmergencyType) {
+	if emgType < 0 || emgType > 9 {
+		w.fail(ErrInvalidEmgID)
+		return
+	}
+	if _, ok := emergencyTypeOf('0' + byte(emgType)); !ok {
+		w.fail(ErrInvalidEmgID)
+		return
+	}
+	w.Field(strconv.Itoa(int(emgType)))
+}
+
+func (w *AsciiWriter) EvtID(evtType EventType) {
+	if evtType < 0 || evtType > 9 {
+		w.fail(ErrInvalidEvtID)
+		return
+	}
+	if _, ok := eventTypeOf('0' + byte(evtType)); !ok {
+		w.fail(ErrInvalidEvtID)
+		return
+	}
+	w.Field(strconv.Itoa(int(evtType)))
+}
+
+func (w *AsciiWriter) AltID(altType AlertType) {
+	code := strconv.Itoa(int(altType))
+	if _, ok := alertTypeOf(code); !ok {
+		w.fail(ErrInvalidAltID)
+		return
+	}
+	w.Field(code)
+}
+
+// GeoAltID writes an AltID followed by the group of a geofence, as read by AsciiGeoAltID. Only single digit codes
+// can be sent with a circular geofence group.
+func (w *AsciiWriter) GeoAltID(altType AlertType, fence FenceType, groupID int) {
+	code := strconv.Itoa(int(altType))
+	if _, ok := alertTypeOf(code); !ok {
+		w.fail(ErrInvalidAltID)
+		return
+	}
+	switch {
+	case fence == NoFence:
+		w.Field(code)
+	case fence == CircleFence && len(code) == 1 && groupID >= 0 && groupID <= 999:
+		w.Field(fmt.Sprintf("%s%03d", code, groupID))
+	case fence == PolygonFence && groupID >= 0 && groupID <= 99:
+		w.Field(fmt.Sprintf("%sP%02d", code, groupID))
+	default:
+		w.fail(ErrInvalidAltID)
+	}
+}
+
+func (w *AsciiWriter) ADC(adc float32) {
+	w.float(adc, 1, 2, 2, false, ErrInvalidADC)
+}
+
+// Data writes the length and the content of a block of external data.
+func (w *AsciiWriter) Data(data []byte) {
+	w.digits(uint64(len(data)), 1, 5, ErrInvalidHLen)
+	if w.err != nil {
+		return
+	}
+	w.buf.WriteByte(Separator)
+	w.buf.Write(data)
+}
+
+// Checksum writes the checksum as two hex digits.
+func (w *AsciiWriter) Checksum(chk uint8) {
+	w.Field(fmt.Sprintf("%02X", chk))
+}
@@ -0,0 +1,318 @@
+package st
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/larixsource/suntech/lexer"
+)
+
+// CmdAction is the action of a CMD command.
+type CmdAction int
+
+const (
+	UnknownCmd CmdAction = iota
+	StatusReqCmd
+	ResetCmd
+	PresetCmd
+	PresetACmd
+	AckEmergCmd
+	Enable1Cmd
+	Disable1Cmd
+	Enable2Cmd
+	Disable2Cmd
+	Enable3Cmd
+	Disable3Cmd
+	ReqIMSICmd
+	ReqICCIDCmd
+	ReqVerCmd
+	StartCounterOdoCmd
+	StopCountOdoCmd
+	ReqRPMUCmd
+	ReqDriverIDCmd
+	ReleaseDIDOutControlCmd
+	ReqTParamCmd
+	EraseAllCmd
+	SetOdometerCmd
+	InitDistCmd
+	InitMsgNoCmd
+	SetHMeterCmd
+	InitCircleGeoCmd
+	ReqCircleGeoCmd
+	ReqOwnNoCmd
+	SetOwnNoCmd
+	ReqVolCmd
+	SetVolCmd
+	RebootCmd
+	EnableSvrLockCmd
+	DisableSvrLockCmd
+	RestartAntiTheft2Cmd
+	SetSMSNoOfPanicCmd
+	ReqSMSNoOfPanicCmd
+	SetGoogleMapCmd
+	ReqGoogleMapCmd
+	ReqShortTestCmd
+	ImproveBatteryLifeCmd
+)
+
+// cmdNames are the names of the actions, as sent to the device.
+var cmdNames = map[CmdAction]string{
+	StatusReqCmd:            "StatusReq",
+	ResetCmd:                "Reset",
+	PresetCmd:               "Preset",
+	PresetACmd:              "Preset A",
+	AckEmergCmd:             "AckEmerg",
+	Enable1Cmd:              "Enable1",
+	Disable1Cmd:             "Disable1",
+	Enable2Cmd:              "Enable2",
+	Disable2Cmd:             "Disable2",
+	Enable3Cmd:              "Enable3",
+	Disable3Cmd:             "Disable3",
+	ReqIMSICmd:              "ReqIMSI",
+	ReqICCIDCmd:             "ReqICCID",
+	ReqVerCmd:               "ReqVer",
+	StartCounterOdoCmd:      "StartCounterOdo",
+	StopCountOdoCmd:         "StopCountOdo",
+	ReqRPMUCmd:              "ReqRPMU",
+	ReqDriverIDCmd:          "ReqDriverID",
+	ReleaseDIDOutControlCmd: "ReleaseDIDOutControl",
+	ReqTParamCmd:            "ReqTParam",
+	EraseAllCmd:             "EraseAll",
+	SetOdometerCmd:          "SetOdometer",
+	InitDistCmd:             "InitDist",
+	InitMsgNoCmd:            "InitMsgNo",
+	SetHMeterCmd:            "SetHMeter",
+	InitCircleGeoCmd:        "InitCircleGeo",
+	ReqCircleGeoCmd:         "ReqCircleGeo",
+	ReqOwnNoCmd:             "ReqOwnNo",
+	SetOwnNoCmd:             "SetOwnNo",
+	ReqVolCmd:               "ReqVol",
+	SetVolCmd:               "SetVol",
+	RebootCmd:               "Reboot",
+	EnableSvrLockCmd:        "EnableSvrLock",
+	DisableSvrLockCmd:       "DisableSvrLock",
+	RestartAntiTheft2Cmd:    "RestartAntiTheft2",
+	SetSMSNoOfPanicCmd:      "SetSMSNoOfPanic",
+	ReqSMSNoOfPanicCmd:      "ReqSMSNoOfPanic",
+	SetGoogleMapCmd:         "SetGoogleMap",
+	ReqGoogleMapCmd:         "ReqGoogleMap",
+	ReqShortTestCmd:         "ReqShortTest",
+	ImproveBatteryLifeCmd:   "ImproveBatteryLife",
+}
+
+// cmdRespNames are the names used by the responses that don't repeat the name of the action.
+var cmdRespNames = map[string]CmdAction{
+	"preseta":      PresetACmd,
+	"circlegeoreq": ReqCircleGeoCmd,
+	"reqteleparam": ReqTParamCmd,
+	"nodriverid":   ReqDriverIDCmd,
+}
+
+// cmdValues checks the values of the actions that need one (like SetOdometer=999999999).
+var cmdValues = map[CmdAction]func(v string) bool{
+	StopCountOdoCmd:       func(v string) bool { return isDigits(v) && len(v) <= 10 },
+	SetOdometerCmd:        func(v string) bool { return isDigits(v) && len(v) <= 10 },
+	SetHMeterCmd:          func(v string) bool { return isDigits(v) && len(v) <= 7 },
+	SetOwnNoCmd:           isPhone,
+	SetVolCmd:             func(v string) bool { return isDigits(v) && len(v) <= 3 },
+	SetSMSNoOfPanicCmd:    isPhone,
+	SetGoogleMapCmd:       func(v string) bool { return v != "" && len(v) <= 100 },
+	ImproveBatteryLifeCmd: func(v string) bool { return v == "0" || v == "1" },
+}
+
+func isPhone(v string) bool {
+	return isDigits(strings.TrimPrefix(v, "+")) && len(v) <= 20
+}
+
+func (a CmdAction) String() string {
+	if name, ok := cmdNames[a]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// CmdActionOf returns the action of the given name, as sent to the device or as returned in a response (the case is
+// ignored, as some responses change it, like SetHmeter).
+func CmdActionOf(name string) (CmdAction, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if a, ok := cmdRespNames[name]; ok {
+		return a, true
+	}
+	for a, n := range cmdNames {
+		if strings.ToLower(n) == name {
+			return a, true
+		}
+	}
+	return UnknownCmd, false
+}
+
+// Cmd is a CMD command: an action, with a value for the Set actions (and a few others, like StopCountOdo).
+type Cmd struct {
+	Action CmdAction
+	Value  string
+}
+
+func (c *Cmd) EncodeParams(w *AsciiWriter) {
+	name, ok := cmdNames[c.Action]
+	if !ok {
+		w.fail(&ParamError{Cmd: "CMD", Param: "Action", Value: int(c.Action)})
+		return
+	}
+	valid, withValue := cmdValues[c.Action]
+	switch {
+	case !withValue && c.Value == "":
+		w.Field(name)
+	case withValue && valid(c.Value) && !strings.ContainsAny(c.Value, ";\r\n"):
+		w.Field(name + "=" + c.Value)
+	default:
+		w.fail(&ParamError{Cmd: "CMD", Param: name, Value: c.Value})
+	}
+}
+
+func (c *Cmd) Validate() error {
+	return validate(c)
+}
+
+// DecodeCmd decodes the action (and value) of a CMD command.
+func DecodeCmd(field string) (Cmd, error) {
+	name, value := field, ""
+	if i := strings.IndexByte(field, '='); i >= 0 {
+		name, value = field[:i], field[i+1:]
+	}
+	action, ok := CmdActionOf(name)
+	if !ok {
+		return Cmd{}, &ParamError{Cmd: "CMD", Param: "Action", Value: field}
+	}
+	return Cmd{Action: action, Value: value}, nil
+}
+
+// CmdExtraInfo is the position sent in the responses of the Enable and Disable actions, and the mode.
+type CmdExtraInfo struct {
+	Position
+	Mode ModeType
+}
+
+// cmdExtraInfoCount is the count of fields of the extra info.
+const cmdExtraInfoCount = 13
+
+// CmdResult is the content of the response of a CMD command.
+type CmdResult struct {
+	Cmd
+
+	// NoUse is true when the action isn't available, because of the configuration of the input or output (like
+	// Enable1NoUse)
+	NoUse bool
+
+	// NoData is true when the device has nothing to return (like ReqSMSNoOfPanic;NoData or NoDriverID)
+	NoData bool
+
+	IMSI    string
+	ICCID   string
+	Version string
+
+	// Extra is the position returned by the Enable and Disable actions
+	Extra *CmdExtraInfo
+
+	// Config is the configuration returned by the Preset and PresetA actions
+	Config *DeviceConfig
+
+	// Params holds the fields after the action, like the settings returned by Preset
+	Params []string
+}
+
+// DecodeCmdResult decodes the response of a CMD command, the fields after the SwVer. The value of the result is the
+// one of a Set action (like SetOdometer=999999999), or the single field after the action (like ReqVol;200).
+func DecodeCmdResult(fields []string) (*CmdResult, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid CMD parameters count: %d", len(fields))
+	}
+	res := &CmdResult{
+		Params: fields[1:],
+	}
+
+	name, value, withValue := cmdResultName(fields[0])
+	if withValue {
+		res.Value = value
+	} else if len(res.Params) == 1 {
+		res.Value = res.Params[0]
+	}
+	if strings.HasSuffix(name, "NoUse") {
+		res.NoUse = true
+		name = strings.TrimSuffix(name, "NoUse")
+	}
+	action, ok := CmdActionOf(name)
+	if !ok {
+		return nil, &ParamError{Cmd: "CMD", Param: "Action", Value: fields[0]}
+	}
+	res.Action = action
+	if strings.EqualFold(name, "NoDriverID") || (len(res.Params) == 1 && res.Params[0] == "NoData") {
+		res.NoData = true
+		res.Value = ""
+		return res, nil
+	}
+
+	switch action {
+	case ReqIMSICmd:
+		res.IMSI = res.Value
+	case ReqICCIDCmd:
+		res.ICCID = res.Value
+	case ReqVerCmd:
+		res.Version = res.Value
+	case PresetCmd, PresetACmd:
+		cfg, err := DecodePreset(res.Params)
+		if err != nil {
+			return nil, err
+		}
+		res.Config = cfg
+	case Enable1Cmd, Disable1Cmd, Enable2Cmd, Disable2Cmd, Enable3Cmd, Disable3Cmd:
+		if len(res.Params) == 0 {
+			break
+		}
+		extra, err := decodeCmdExtraInfo(res.Params)
+		if err != nil {
+			return nil, err
+		}
+		res.Extra = extra
+		res.Value = ""
+	}
+	return res, nil
+}
+
+// CmdResultAction returns the action of a CMD response, given its first field after the SwVer (like "Enable1NoUse" or
+// "SetOdometer=999999999").
+func CmdResultAction(field string) (CmdAction, bool) {
+	name, _, _ := cmdResultName(field)
+	return CmdActionOf(strings.TrimSuffix(name, "NoUse"))
+}
+
+// cmdResultName splits the first field of a CMD response into the name of the action and its value (for the Set
+// actions).
+func cmdResultName(field string) (name, value string, withValue bool) {
+	// some responses have spaces around the name of the action
+	name = strings.TrimSpace(field)
+	if i := strings.IndexByte(name, '='); i >= 0 {
+		return strings.TrimSpace(name[:i]), name[i+1:], true
+	}
+	return name, "", false
+}
+
+// decodeCmdExtraInfo decodes the extra info, with the same fields of the reports (from the date to the mode).
+func decodeCmdExtraInfo(fields []string) (*CmdExtraInfo, error) {
+	if len(fields) != cmdExtraInfoCount {
+		return nil, fmt.Errorf("invalid CMD extra info count: %d (expected %d)", len(fields), cmdExtraInfoCount)
+	}
+	pos, err := DecodePosition(fields[:PositionFieldsCount])
+	if err != nil {
+		return nil, err
+	}
+	extra := &CmdExtraInfo{
+		Position: *pos,
+	}
+	lex := &lexer.Lexer{
+		Reader: strings.NewReader(fields[PositionFieldsCount] + string(Separator)),
+	}
+	if extra.Mode, _, err = AsciiMode(lex); err != nil {
+		return nil, err
+	}
+	return extra, nil
+}
@@ -0,0 +1,610 @@
+package st
+
+// Parameters of the configuration commands, shared by all the models. Each type validates its ranges while being
+// encoded (see Command), and decodes the parameters of a command or of its response.
+
+// NTWParams are the network parameters (NTW command).
+type NTWParams struct {
+	Auth       string
+	APN        string
+	UserID     string
+	UserPwd    string
+	ServerIP   string
+	ServerPort int
+	BackupIP   string
+	BackupPort int
+	SMSNo      string
+	PINNo      string
+}
+
+func (p *NTWParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "NTW", w: w}
+	e.str("Auth", p.Auth, 2)
+	e.str("APN", p.APN, 40)
+	e.str("UserID", p.UserID, 30)
+	e.str("UserPwd", p.UserPwd, 30)
+	if p.ServerIP == "" {
+		e.fail("ServerIP", p.ServerIP)
+	}
+	e.str("ServerIP", p.ServerIP, 64)
+	e.int("ServerPort", p.ServerPort, 1, 65535)
+	e.str("BackupIP", p.BackupIP, 64)
+	e.optInt("BackupPort", p.BackupPort, 65535)
+	e.str("SMSNo", p.SMSNo, 20)
+	e.str("PINNo", p.PINNo, 8)
+}
+
+func (p *NTWParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("NTW", fields, 10)
+	if err != nil {
+		return err
+	}
+	p.Auth = d.str()
+	p.APN = d.str()
+	p.UserID = d.str()
+	p.UserPwd = d.str()
+	p.ServerIP = d.str()
+	p.ServerPort = d.int("ServerPort")
+	p.BackupIP = d.str()
+	p.BackupPort = d.int("BackupPort")
+	p.SMSNo = d.str()
+	p.PINNo = d.str()
+	return d.err
+}
+
+func (p *NTWParams) Validate() error {
+	return validate(p)
+}
+
+// RPTParams are the report parameters (RPT command). Intervals are in seconds, and the distance in meters.
+type RPTParams struct {
+	ParkingInterval    int
+	DrivingInterval    int
+	EmergencyInterval  int
+	EmergencyCount     int
+	SendDistance       int
+	KeepAliveInterval  int
+	SMSParkingInterval int
+	SMSDrivingInterval int
+	SMSPackNo          int
+}
+
+func (p *RPTParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "RPT", w: w}
+	e.int("ParkingInterval", p.ParkingInterval, 0, 65535)
+	e.int("DrivingInterval", p.DrivingInterval, 0, 65535)
+	e.int("EmergencyInterval", p.EmergencyInterval, 0, 65535)
+	e.int("EmergencyCount", p.EmergencyCount, 0, 255)
+	e.int("SendDistance", p.SendDistance, 0, 65535)
+	e.int("KeepAliveInterval", p.KeepAliveInterval, 0, 65535)
+	e.int("SMSParkingInterval", p.SMSParkingInterval, 0, 65535)
+	e.int("SMSDrivingInterval", p.SMSDrivingInterval, 0, 65535)
+	e.int("SMSPackNo", p.SMSPackNo, 0, 255)
+}
+
+func (p *RPTParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("RPT", fields, 9)
+	if err != nil {
+		return err
+	}
+	p.ParkingInterval = d.int("ParkingInterval")
+	p.DrivingInterval = d.int("DrivingInterval")
+	p.EmergencyInterval = d.int("EmergencyInterval")
+	p.EmergencyCount = d.int("EmergencyCount")
+	p.SendDistance = d.int("SendDistance")
+	p.KeepAliveInterval = d.int("KeepAliveInterval")
+	p.SMSParkingInterval = d.int("SMSParkingInterval")
+	p.SMSDrivingInterval = d.int("SMSDrivingInterval")
+	p.SMSPackNo = d.int("SMSPackNo")
+	return d.err
+}
+
+func (p *RPTParams) Validate() error {
+	return validate(p)
+}
+
+// EVTParams are the event parameters (EVT command, not to be confused with the EVT report).
+type EVTParams struct {
+	Ignition    int
+	ParkingTime int
+	ParkingLock int
+	InType      [3]int
+	InChat      [3]int
+	OutType     [2]int
+
+	// the remaining events, in the order of the protocol
+	GPSAntennaCut  int
+	Jamming        int
+	JammingTime    int
+	PowerDown      int
+	BatteryLow     int
+	Shock          int
+	ShockLevel     int
+	Collision      int
+	CollisionLevel int
+	RouteDeviation int
+	IButton        int
+	DeepSleep      int
+	DeepSleepTime  int
+}
+
+// EVTParamsCount is the count of parameters of the EVT command.
+const EVTParamsCount = 24
+
+func (p *EVTParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "EVT", w: w}
+	e.int("Ignition", p.Ignition, 0, 9)
+	e.int("ParkingTime", p.ParkingTime, 0, 65535)
+	e.int("ParkingLock", p.ParkingLock, 0, 65535)
+	for _, v := range p.InType {
+		e.int("InType", v, 0, 99)
+	}
+	for _, v := range p.InChat {
+		e.int("InChat", v, 0, 65535)
+	}
+	for _, v := range p.OutType {
+		e.int("OutType", v, 0, 99)
+	}
+	e.int("GPSAntennaCut", p.GPSAntennaCut, 0, 65535)
+	e.int("Jamming", p.Jamming, 0, 65535)
+	e.int("JammingTime", p.JammingTime, 0, 65535)
+	e.int("PowerDown", p.PowerDown, 0, 65535)
+	e.int("BatteryLow", p.BatteryLow, 0, 65535)
+	e.int("Shock", p.Shock, 0, 65535)
+	e.int("ShockLevel", p.ShockLevel, 0, 65535)
+	e.int("Collision", p.Collision, 0, 65535)
+	e.int("CollisionLevel", p.CollisionLevel, 0, 65535)
+	e.int("RouteDeviation", p.RouteDeviation, 0, 65535)
+	e.int("IButton", p.IButton, 0, 65535)
+	e.int("DeepSleep", p.DeepSleep, 0, 65535)
+	e.int("DeepSleepTime", p.DeepSleepTime, 0, 65535)
+}
+
+func (p *EVTParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("EVT", fields, EVTParamsCount)
+	if err != nil {
+		return err
+	}
+	p.Ignition = d.int("Ignition")
+	p.ParkingTime = d.int("ParkingTime")
+	p.ParkingLock = d.int("ParkingLock")
+	for i := range p.InType {
+		p.InType[i] = d.int("InType")
+	}
+	for i := range p.InChat {
+		p.InChat[i] = d.int("InChat")
+	}
+	for i := range p.OutType {
+		p.OutType[i] = d.int("OutType")
+	}
+	p.GPSAntennaCut = d.int("GPSAntennaCut")
+	p.Jamming = d.int("Jamming")
+	p.JammingTime = d.int("JammingTime")
+	p.PowerDown = d.int("PowerDown")
+	p.BatteryLow = d.int("BatteryLow")
+	p.Shock = d.int("Shock")
+	p.ShockLevel = d.int("ShockLevel")
+	p.Collision = d.int("Collision")
+	p.CollisionLevel = d.int("CollisionLevel")
+	p.RouteDeviation = d.int("RouteDeviation")
+	p.IButton = d.int("IButton")
+	p.DeepSleep = d.int("DeepSleep")
+	p.DeepSleepTime = d.int("DeepSleepTime")
+	return d.err
+}
+
+func (p *EVTParams) Validate() error {
+	return validate(p)
+}
+
+// GSMParams are the GSM parameters (GSM command): the phone numbers that receive SMS and voice calls.
+type GSMParams struct {
+	SMSMode  int
+	SMSNo    [4]string
+	CallMode int
+	CallNo   [7]string
+}
+
+func (p *GSMParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "GSM", w: w}
+	e.int("SMSMode", p.SMSMode, 0, 9)
+	for _, v := range p.SMSNo {
+		e.str("SMSNo", v, 20)
+	}
+	e.int("CallMode", p.CallMode, 0, 9)
+	for _, v := range p.CallNo {
+		e.str("CallNo", v, 20)
+	}
+}
+
+func (p *GSMParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("GSM", fields, 13)
+	if err != nil {
+		return err
+	}
+	p.SMSMode = d.int("SMSMode")
+	for i := range p.SMSNo {
+		p.SMSNo[i] = d.str()
+	}
+	p.CallMode = d.int("CallMode")
+	for i := range p.CallNo {
+		p.CallNo[i] = d.str()
+	}
+	return d.err
+}
+
+func (p *GSMParams) Validate() error {
+	return validate(p)
+}
+
+// SVCParams are the service parameters (SVC command).
+type SVCParams struct {
+	Parking     int
+	ParkingTime int
+	SpeedLimit  int
+
+	// the remaining service options, in the order of the protocol
+	AutoReset         int
+	ResetInterval     int
+	SMSReport         int
+	AngleReport       int
+	StaticFilter      int
+	IgnitionOffReport int
+	AngleThreshold    int
+	DistanceFilter    int
+	SMSInterval       int
+	Odometer          int
+}
+
+func (p *SVCParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "SVC", w: w}
+	e.int("Parking", p.Parking, 0, 1)
+	e.int("ParkingTime", p.ParkingTime, 0, 65535)
+	e.int("SpeedLimit", p.SpeedLimit, 0, 255)
+	e.int("AutoReset", p.AutoReset, 0, 65535)
+	e.int("ResetInterval", p.ResetInterval, 0, 65535)
+	e.int("SMSReport", p.SMSReport, 0, 65535)
+	e.int("AngleReport", p.AngleReport, 0, 65535)
+	e.int("StaticFilter", p.StaticFilter, 0, 65535)
+	e.int("IgnitionOffReport", p.IgnitionOffReport, 0, 65535)
+	e.int("AngleThreshold", p.AngleThreshold, 0, 65535)
+	e.int("DistanceFilter", p.DistanceFilter, 0, 65535)
+	e.int("SMSInterval", p.SMSInterval, 0, 65535)
+	e.int("Odometer", p.Odometer, 0, 65535)
+}
+
+func (p *SVCParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("SVC", fields, 13)
+	if err != nil {
+		return err
+	}
+	p.Parking = d.int("Parking")
+	p.ParkingTime = d.int("ParkingTime")
+	p.SpeedLimit = d.int("SpeedLimit")
+	p.AutoReset = d.int("AutoReset")
+	p.ResetInterval = d.int("ResetInterval")
+	p.SMSReport = d.int("SMSReport")
+	p.AngleReport = d.int("AngleReport")
+	p.StaticFilter = d.int("StaticFilter")
+	p.IgnitionOffReport = d.int("IgnitionOffReport")
+	p.AngleThreshold = d.int("AngleThreshold")
+	p.DistanceFilter = d.int("DistanceFilter")
+	p.SMSInterval = d.int("SMSInterval")
+	p.Odometer = d.int("Odometer")
+	return d.err
+}
+
+func (p *SVCParams) Validate() error {
+	return validate(p)
+}
+
+// MBVParams are the main battery voltage parameters (MBV command).
+type MBVParams struct {
+	// Levels holds the voltage levels (in volts), in the order of the protocol
+	Levels [7]float32
+}
+
+func (p *MBVParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "MBV", w: w}
+	for i, v := range p.Levels {
+		e.float("Levels", v, mbvPrec[i], 0, 99.99)
+	}
+}
+
+// mbvPrec are the decimals of each MBV level: the thresholds are written with the minimum needed, and the
+// fourth and fifth levels always with 2 (like "0;22;19;8.00;18.00;0;0").
+var mbvPrec = [7]int{-1, -1, -1, 2, 2, -1, -1}
+
+func (p *MBVParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("MBV", fields, 7)
+	if err != nil {
+		return err
+	}
+	for i := range p.Levels {
+		p.Levels[i] = d.float("Levels")
+	}
+	return d.err
+}
+
+func (p *MBVParams) Validate() error {
+	return validate(p)
+}
+
+// MSRParams are the motion sensor parameters (MSR command). Thresholds are in g.
+type MSRParams struct {
+	Interval     int
+	Acceleration float32
+	Deceleration float32
+	Impact       float32
+}
+
+func (p *MSRParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "MSR", w: w}
+	e.int("Interval", p.Interval, 0, 65535)
+	e.float("Acceleration", p.Acceleration, -1, 0, 16)
+	e.float("Deceleration", p.Deceleration, -1, 0, 16)
+	e.float("Impact", p.Impact, -1, 0, 16)
+}
+
+func (p *MSRParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("MSR", fields, 4)
+	if err != nil {
+		return err
+	}
+	p.Interval = d.int("Interval")
+	p.Acceleration = d.float("Acceleration")
+	p.Deceleration = d.float("Deceleration")
+	p.Impact = d.float("Impact")
+	return d.err
+}
+
+func (p *MSRParams) Validate() error {
+	return validate(p)
+}
+
+// ADPParams are the additional connection parameters (ADP command). Protocols are "U" (UDP) or "T" (TCP).
+type ADPParams struct {
+	MainProtocol   string
+	BackupProtocol string
+	Mode           int
+	Port           int
+
+	// the remaining parameters, in the order of the protocol
+	BackupMode        int
+	BackupPort        int
+	KeepAliveMode     int
+	KeepAliveInterval int
+	Retries           int
+	RetryInterval     int
+}
+
+func (p *ADPParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "ADP", w: w}
+	e.oneOf("MainProtocol", p.MainProtocol, "U", "T")
+	e.oneOf("BackupProtocol", p.BackupProtocol, "U", "T")
+	e.int("Mode", p.Mode, 0, 9)
+	e.int("Port", p.Port, 0, 65535)
+	e.int("BackupMode", p.BackupMode, 0, 65535)
+	e.int("BackupPort", p.BackupPort, 0, 65535)
+	e.int("KeepAliveMode", p.KeepAliveMode, 0, 65535)
+	e.int("KeepAliveInterval", p.KeepAliveInterval, 0, 65535)
+	e.int("Retries", p.Retries, 0, 65535)
+	e.int("RetryInterval", p.RetryInterval, 0, 65535)
+}
+
+func (p *ADPParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("ADP", fields, 10)
+	if err != nil {
+		return err
+	}
+	p.MainProtocol = d.str()
+	p.BackupProtocol = d.str()
+	p.Mode = d.int("Mode")
+	p.Port = d.int("Port")
+	p.BackupMode = d.int("BackupMode")
+	p.BackupPort = d.int("BackupPort")
+	p.KeepAliveMode = d.int("KeepAliveMode")
+	p.KeepAliveInterval = d.int("KeepAliveInterval")
+	p.Retries = d.int("Retries")
+	p.RetryInterval = d.int("RetryInterval")
+	return d.err
+}
+
+func (p *ADPParams) Validate() error {
+	return validate(p)
+}
+
+// NPTParamsCount is the count of parameters of the NPT command.
+const NPTParamsCount = 14
+
+// NPTParams are the parameters of the NPT command, in the order of the protocol.
+type NPTParams struct {
+	Mode               int
+	Protocol           int
+	Timeout            int
+	Reconnect          int
+	ReconnectInterval  int
+	DisconnectTime     int
+	ConnectTime        int
+	SendCount          int
+	SendInterval       int
+	SendLimit          int
+	BackupSendCount    int
+	BackupSendInterval int
+	BackupSendLimit    int
+	Option             int
+}
+
+func (p *NPTParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "NPT", w: w}
+	e.int("Mode", p.Mode, 0, 65535)
+	e.int("Protocol", p.Protocol, 0, 65535)
+	e.int("Timeout", p.Timeout, 0, 65535)
+	e.int("Reconnect", p.Reconnect, 0, 65535)
+	e.int("ReconnectInterval", p.ReconnectInterval, 0, 65535)
+	e.int("DisconnectTime", p.DisconnectTime, 0, 65535)
+	e.int("ConnectTime", p.ConnectTime, 0, 65535)
+	e.int("SendCount", p.SendCount, 0, 65535)
+	e.int("SendInterval", p.SendInterval, 0, 65535)
+	e.int("SendLimit", p.SendLimit, 0, 65535)
+	e.int("BackupSendCount", p.BackupSendCount, 0, 65535)
+	e.int("BackupSendInterval", p.BackupSendInterval, 0, 65535)
+	e.int("BackupSendLimit", p.BackupSendLimit, 0, 65535)
+	e.int("Option", p.Option, 0, 65535)
+}
+
+func (p *NPTParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("NPT", fields, NPTParamsCount)
+	if err != nil {
+		return err
+	}
+	p.decode(d)
+	return d.err
+}
+
+// decode decodes the parameters read by d, the missing ones are left as zero.
+func (p *NPTParams) decode(d *paramsDecoder) {
+	p.Mode = d.int("Mode")
+	p.Protocol = d.int("Protocol")
+	p.Timeout = d.int("Timeout")
+	p.Reconnect = d.int("Reconnect")
+	p.ReconnectInterval = d.int("ReconnectInterval")
+	p.DisconnectTime = d.int("DisconnectTime")
+	p.ConnectTime = d.int("ConnectTime")
+	p.SendCount = d.int("SendCount")
+	p.SendInterval = d.int("SendInterval")
+	p.SendLimit = d.int("SendLimit")
+	p.BackupSendCount = d.int("BackupSendCount")
+	p.BackupSendInterval = d.int("BackupSendInterval")
+	p.BackupSendLimit = d.int("BackupSendLimit")
+	p.Option = d.int("Option")
+}
+
+func (p *NPTParams) Validate() error {
+	return validate(p)
+}
+
+// DPAParams are the driver pattern analysis parameters (DPA command), the thresholds of the DPA alerts.
+type DPAParams struct {
+	Mode         int
+	MinSpeed     float32
+	Acceleration float32
+	Braking      float32
+	SharpTurn    float32
+	OverSpeed    float32
+}
+
+func (p *DPAParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "DPA", w: w}
+	e.int("Mode", p.Mode, 0, 9)
+	e.float("MinSpeed", p.MinSpeed, 1, 0, 999.9)
+	e.float("Acceleration", p.Acceleration, 1, 0, 999.9)
+	e.float("Braking", p.Braking, 1, 0, 999.9)
+	e.float("SharpTurn", p.SharpTurn, 1, 0, 999.9)
+	e.float("OverSpeed", p.OverSpeed, 1, 0, 999.9)
+}
+
+func (p *DPAParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("DPA", fields, 6)
+	if err != nil {
+		return err
+	}
+	p.Mode = d.int("Mode")
+	p.MinSpeed = d.float("MinSpeed")
+	p.Acceleration = d.float("Acceleration")
+	p.Braking = d.float("Braking")
+	p.SharpTurn = d.float("SharpTurn")
+	p.OverSpeed = d.float("OverSpeed")
+	return d.err
+}
+
+func (p *DPAParams) Validate() error {
+	return validate(p)
+}
+
+// Threshold returns the threshold that triggers a DPA alert (like FastBrakingFromDPAAlt), false if alt isn't a DPA
+// alert.
+func (p *DPAParams) Threshold(alt AlertType) (float32, bool) {
+	switch alt {
+	case FastAccelerationFromDPAAlt:
+		return p.Acceleration, true
+	case FastBrakingFromDPAAlt:
+		return p.Braking, true
+	case SharpTurnFromDPAAlt:
+		return p.SharpTurn, true
+	case OverSpeedFromDPAAlt:
+		return p.OverSpeed, true
+	default:
+		return 0, false
+	}
+}
+
+// LTMParams are the parameters of the LTM command.
+type LTMParams struct {
+	Enable int
+	Mode   int
+	Option int
+}
+
+func (p *LTMParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "LTM", w: w}
+	e.int("Enable", p.Enable, 0, 1)
+	e.int("Mode", p.Mode, 0, 255)
+	e.int("Option", p.Option, 0, 255)
+}
+
+func (p *LTMParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("LTM", fields, 3)
+	if err != nil {
+		return err
+	}
+	p.Enable = d.int("Enable")
+	p.Mode = d.int("Mode")
+	p.Option = d.int("Option")
+	return d.err
+}
+
+func (p *LTMParams) Validate() error {
+	return validate(p)
+}
+
+// CGFParams are the parameters of a circular geofence (CGF command).
+type CGFParams struct {
+	GeoID     int
+	Active    bool
+	Latitude  float32
+	Longitude float32
+
+	// Radius is in meters
+	Radius int
+	In     bool
+	Out    bool
+}
+
+func (p *CGFParams) EncodeParams(w *AsciiWriter) {
+	e := paramsEncoder{cmd: "CGF", w: w}
+	e.int("GeoID", p.GeoID, 0, 999)
+	w.Bit(p.Active)
+	if p.Latitude < -90 || p.Latitude > 90 {
+		e.fail("Latitude", p.Latitude)
+	}
+	w.Lat(p.Latitude)
+	if p.Longitude < -180 || p.Longitude > 180 {
+		e.fail("Longitude", p.Longitude)
+	}
+	w.Lon(p.Longitude)
+	e.int("Radius", p.Radius, 0, 99999)
+	w.Bit(p.In)
+	w.Bit(p.Out)
+}
+
+func (p *CGFParams) Validate() error {
+	return validate(p)
+}
+
+// validate checks the ranges of the parameters, encoding them.
+func validate(params ParamsEncoder) error {
+	var w AsciiWriter
+	params.EncodeParams(&w)
+	return w.Err()
+}
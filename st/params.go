@@ -0,0 +1,210 @@
+package st
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CmdVersion is the version field of the commands sent to a device.
+const CmdVersion = "02"
+
+// ParamError reports an invalid parameter of a configuration command, found while building or parsing it.
+type ParamError struct {
+	Cmd   string
+	Param string
+	Value interface{}
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid %s parameter %s: %q", e.Cmd, e.Param, fmt.Sprint(e.Value))
+}
+
+// ParamsEncoder is implemented by the parameters of the configuration commands.
+type ParamsEncoder interface {
+	EncodeParams(w *AsciiWriter)
+}
+
+// ParamsFunc is a function used as a ParamsEncoder.
+type ParamsFunc func(w *AsciiWriter)
+
+func (f ParamsFunc) EncodeParams(w *AsciiWriter) {
+	f(w)
+}
+
+// ParamsDecoder is implemented by the parameters of the configuration commands.
+type ParamsDecoder interface {
+	DecodeParams(fields []string) error
+}
+
+// Command builds the frame of a configuration command (without the CR): the header (like "ST300NTW"), the DevID,
+// the version (CmdVersion if empty) and the parameters.
+func Command(hdr, devID, ver string, params ParamsEncoder) ([]byte, error) {
+	if ver == "" {
+		ver = CmdVersion
+	}
+	var w AsciiWriter
+	w.Hdr(hdr)
+	w.DevID(devID)
+	if len(ver) > 3 || strings.Trim(ver, "0123456789") != "" {
+		w.fail(ErrInvalidSwVer)
+	}
+	w.Field(ver)
+	params.EncodeParams(&w)
+	return w.Command()
+}
+
+// SplitParams splits the parameters of a configuration command (the rest of the frame, without the CR).
+func SplitParams(b []byte) []string {
+	var fields []string
+	for _, f := range bytes.Split(b, []byte{Separator}) {
+		fields = append(fields, string(f))
+	}
+	return fields
+}
+
+// DecodeCmdHdr decodes the start of a command ("DevID;Ver;") or of its response ("Res;DevID;SwVer;"), given all the
+// fields of the frame after the header. The DevID isn't required to have 9 digits, as some spec examples use shorter
+// ones. Returns the remaining fields.
+func DecodeCmdHdr(fields []string) (resp bool, devID string, swVer string, rest []string, err error) {
+	if len(fields) > 0 && fields[0] == string(ResLiteral) {
+		resp = true
+		fields = fields[1:]
+	}
+	if len(fields) < 2 {
+		err = ErrInvalidSwVer
+		return
+	}
+	devID, swVer, rest = fields[0], fields[1], fields[2:]
+	if !isDigits(devID) || len(devID) > 9 {
+		err = ErrInvalidDevID
+		return
+	}
+	// some responses come without the SwVer (like CircleGeoReq;NoData)
+	if resp && swVer != "" && !isDigits(swVer) {
+		swVer, rest = "", fields[1:]
+		return
+	}
+	if !isDigits(swVer) || len(swVer) > 3 {
+		err = ErrInvalidSwVer
+	}
+	return
+}
+
+// paramsDecoder decodes the parameters of a configuration command, in order. The first error found is kept, and
+// the following reads return zero values.
+type paramsDecoder struct {
+	cmd    string
+	fields []string
+	i      int
+	err    error
+}
+
+func newParamsDecoder(cmd string, fields []string, count int) (*paramsDecoder, error) {
+	if len(fields) != count {
+		return nil, fmt.Errorf("invalid %s parameters count: %d (expected %d)", cmd, len(fields), count)
+	}
+	return &paramsDecoder{
+		cmd:    cmd,
+		fields: fields,
+	}, nil
+}
+
+func (d *paramsDecoder) next() (string, bool) {
+	if d.err != nil || d.i >= len(d.fields) {
+		return "", false
+	}
+	f := d.fields[d.i]
+	d.i++
+	return f, true
+}
+
+func (d *paramsDecoder) str() string {
+	f, _ := d.next()
+	return f
+}
+
+// int decodes an integer parameter, an empty one is decoded as zero.
+func (d *paramsDecoder) int(name string) int {
+	f, ok := d.next()
+	if !ok || f == "" {
+		return 0
+	}
+	v, err := strconv.Atoi(f)
+	if err != nil {
+		d.err = &ParamError{Cmd: d.cmd, Param: name, Value: f}
+		return 0
+	}
+	return v
+}
+
+// float decodes a float parameter, an empty one is decoded as zero.
+func (d *paramsDecoder) float(name string) float32 {
+	f, ok := d.next()
+	if !ok || f == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(f, 32)
+	if err != nil {
+		d.err = &ParamError{Cmd: d.cmd, Param: name, Value: f}
+		return 0
+	}
+	return float32(v)
+}
+
+// paramsEncoder writes the parameters of a configuration command, checking their ranges.
+type paramsEncoder struct {
+	cmd string
+	w   *AsciiWriter
+}
+
+func (e paramsEncoder) fail(name string, v interface{}) {
+	e.w.fail(&ParamError{Cmd: e.cmd, Param: name, Value: v})
+}
+
+// str writes a string of up to maxLen chars, that can't contain separators.
+func (e paramsEncoder) str(name, v string, maxLen int) {
+	if len(v) > maxLen || strings.ContainsAny(v, ";\r\n") {
+		e.fail(name, v)
+		return
+	}
+	e.w.Field(v)
+}
+
+// oneOf writes a string that must be one of the given values.
+func (e paramsEncoder) oneOf(name, v string, values ...string) {
+	for _, value := range values {
+		if v == value {
+			e.w.Field(v)
+			return
+		}
+	}
+	e.fail(name, v)
+}
+
+func (e paramsEncoder) int(name string, v, min, max int) {
+	if v < min || v > max {
+		e.fail(name, v)
+		return
+	}
+	e.w.Field(strconv.Itoa(v))
+}
+
+// optInt writes an optional integer, left empty when it's zero.
+func (e paramsEncoder) optInt(name string, v, max int) {
+	if v == 0 {
+		e.w.Field("")
+		return
+	}
+	e.int(name, v, 0, max)
+}
+
+// float writes a float with prec decimals (or the minimum needed, if prec is -1).
+func (e paramsEncoder) float(name string, v float32, prec int, min, max float32) {
+	if v < min || v > max {
+		e.fail(name, v)
+		return
+	}
+	e.w.Field(strconv.FormatFloat(float64(v), 'f', prec, 32))
+}
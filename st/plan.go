@@ -0,0 +1,56 @@
+package st
+
+import (
+	"reflect"
+)
+
+// ConfigCommand is a configuration command of a plan: its name (like "RPT") and its parameters.
+type ConfigCommand struct {
+	Cmd    string
+	Params ParamsEncoder
+}
+
+// PlanConfig returns the configuration commands that change the current configuration of a device into the desired
+// one: one for each section of desired that's different from current (a nil current is taken as unknown, so every
+// section of desired is sent). The sections without a command (DEV) are ignored, and so is NPT when desired comes from
+// a Preset, as its section is partial (see DeviceConfig).
+//
+// The commands follow the order of the Preset, except NTW, which is the last one, because changing the server or the
+// APN could drop the connection used to send the rest.
+func PlanConfig(current, desired *DeviceConfig) []ConfigCommand {
+	if current == nil {
+		current = &DeviceConfig{}
+	}
+
+	var plan []ConfigCommand
+	add := func(cmd string, changed bool, params ParamsEncoder) {
+		if changed {
+			plan = append(plan, ConfigCommand{Cmd: cmd, Params: params})
+		}
+	}
+	add("RPT", desired.RPT != nil && !reflect.DeepEqual(current.RPT, desired.RPT), desired.RPT)
+	add("EVT", desired.EVT != nil && !reflect.DeepEqual(current.EVT, desired.EVT), desired.EVT)
+	add("GSM", desired.GSM != nil && !reflect.DeepEqual(current.GSM, desired.GSM), desired.GSM)
+	add("SVC", desired.SVC != nil && !reflect.DeepEqual(current.SVC, desired.SVC), desired.SVC)
+	add("ADP", desired.ADP != nil && !reflect.DeepEqual(current.ADP, desired.ADP), desired.ADP)
+	add("MSR", desired.MSR != nil && !reflect.DeepEqual(current.MSR, desired.MSR), desired.MSR)
+	add("MBV", desired.MBV != nil && !reflect.DeepEqual(current.MBV, desired.MBV), desired.MBV)
+	add("NPT", desired.NPT != nil && !reflect.DeepEqual(current.NPT, desired.NPT), desired.NPT)
+	add("NTW", desired.NTW != nil && !reflect.DeepEqual(current.NTW, desired.NTW), desired.NTW)
+	return plan
+}
+
+// PlanCommands builds the frames (without the CR) of the commands returned by PlanConfig, given the prefix of their
+// headers (like "ST300"). No frame is returned when the parameters of any command are invalid.
+func PlanCommands(prefix, devID string, current, desired *DeviceConfig) ([][]byte, error) {
+	plan := PlanConfig(current, desired)
+	frames := make([][]byte, 0, len(plan))
+	for _, c := range plan {
+		frame, err := Command(prefix+c.Cmd, devID, "", c.Params)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
@@ -0,0 +1,83 @@
+package st
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/larixsource/suntech/lexer"
+)
+
+// PositionFieldsCount is the count of fields of a Position.
+const PositionFieldsCount = 12
+
+// Position is a timestamped position, with the fields of the reports from the date to the IO. It's sent by the
+// responses of some commands (like the Enable actions of CMD).
+type Position struct {
+	Timestamp  time.Time
+	Cell       string
+	Latitude   float32
+	Longitude  float32
+	Speed      float32
+	Course     float32
+	Satellites uint8
+	GPSFixed   bool
+	Distance   uint32
+	PowerVolt  float32
+	IO         string
+}
+
+// DecodePosition decodes the fields of a position, read as in the reports.
+func DecodePosition(fields []string) (*Position, error) {
+	if len(fields) != PositionFieldsCount {
+		return nil, fmt.Errorf("invalid position fields count: %d (expected %d)", len(fields), PositionFieldsCount)
+	}
+	// the IO is checked apart, as it isn't followed by the mode
+	b := []byte(strings.Join(fields[:PositionFieldsCount-1], string(Separator)))
+	b = append(b, Separator)
+	lex := &lexer.Lexer{
+		Reader: bytes.NewReader(b),
+	}
+
+	pos := &Position{}
+	var err error
+	if pos.Timestamp, _, err = AsciiTimestamp(lex); err != nil {
+		return nil, err
+	}
+	if pos.Cell, _, err = AsciiCell(lex); err != nil {
+		return nil, err
+	}
+	if pos.Latitude, _, err = AsciiLat(lex); err != nil {
+		return nil, err
+	}
+	if pos.Longitude, _, err = AsciiLon(lex); err != nil {
+		return nil, err
+	}
+	if pos.Speed, _, err = AsciiSpeed(lex); err != nil {
+		return nil, err
+	}
+	if pos.Course, _, err = AsciiCourse(lex); err != nil {
+		return nil, err
+	}
+	if pos.Satellites, _, err = AsciiSatellites(lex); err != nil {
+		return nil, err
+	}
+	if pos.GPSFixed, _, err = AsciiFix(lex); err != nil {
+		return nil, err
+	}
+	if pos.Distance, _, err = AsciiDistance(lex); err != nil {
+		return nil, err
+	}
+	if pos.PowerVolt, _, err = AsciiPowerVolt(lex); err != nil {
+		return nil, err
+	}
+	if pos.IO = fields[PositionFieldsCount-1]; !isBits(pos.IO) {
+		return nil, ErrInvalidIO
+	}
+	return pos, nil
+}
+
+func isBits(s string) bool {
+	return s != "" && len(s) <= 8 && strings.Trim(s, "01") == ""
+}
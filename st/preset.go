@@ -0,0 +1,139 @@
+package st
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DeviceConfig is the configuration of a device, as returned by the Preset and PresetA actions of CMD. Preset only
+// sends the NTW, RPT, EVT, GSM, SVC and DEV sections, so the others are nil. The NPT section of PresetA doesn't have
+// all the parameters of the NPT command, so it's only kept in Sections, and NPT is left for a desired configuration.
+type DeviceConfig struct {
+	NTW *NTWParams
+	RPT *RPTParams
+	EVT *EVTParams
+	GSM *GSMParams
+	SVC *SVCParams
+	ADP *ADPParams
+	MSR *MSRParams
+	MBV *MBVParams
+	NPT *NPTParams
+	DEV *DEVParams
+
+	// Sections holds the raw fields of each section, by its tag (like "RPT"). Some sections have fields that aren't
+	// sent by their command, like the first one of RPT, only kept here.
+	Sections map[string][]string
+}
+
+// DEVParams are the device settings of the DEV section of Preset (4 of them) and PresetA (8 of them). They don't have
+// a command, nor a documented meaning, so they're kept in the order of the protocol.
+type DEVParams struct {
+	Params []int
+}
+
+func (p *DEVParams) DecodeParams(fields []string) error {
+	d, err := newParamsDecoder("DEV", fields, len(fields))
+	if err != nil {
+		return err
+	}
+	p.Params = make([]int, len(fields))
+	for i := range p.Params {
+		p.Params[i] = d.int("Params")
+	}
+	return d.err
+}
+
+// presetRPTCount is the count of fields of the RPT section: the ones of the RPT command, after an undocumented one.
+const presetRPTCount = 10
+
+// presetNPTCount is the count of fields of the NPT section: the version (like in some NPT responses), and 11 of the
+// 14 parameters of the NPT command (it doesn't have the two 70s and the last 0 of the spec NPT command).
+const presetNPTCount = 12
+
+// DecodePreset decodes the fields of a Preset or PresetA response (after the action), a sequence of sections, each
+// one starting with its tag (like "NTW;0;internet;...;RPT;60;180;...").
+func DecodePreset(fields []string) (*DeviceConfig, error) {
+	cfg := &DeviceConfig{
+		Sections: make(map[string][]string),
+	}
+
+	var tag string
+	start := 0
+	for i := 0; i <= len(fields); i++ {
+		if i < len(fields) && !isPresetTag(fields[i]) {
+			if i == 0 {
+				return nil, fmt.Errorf("invalid Preset section: %q", fields[i])
+			}
+			continue
+		}
+		if tag != "" {
+			if err := cfg.decodeSection(tag, fields[start:i]); err != nil {
+				return nil, err
+			}
+		}
+		if i < len(fields) {
+			tag, start = fields[i], i+1
+		}
+	}
+	if len(cfg.Sections) == 0 {
+		return nil, errors.New("invalid Preset: no sections")
+	}
+	return cfg, nil
+}
+
+func isPresetTag(s string) bool {
+	switch s {
+	case "NTW", "RPT", "EVT", "GSM", "SVC", "ADP", "MSR", "MBV", "NPT", "DEV":
+		return true
+	default:
+		return false
+	}
+}
+
+func (cfg *DeviceConfig) decodeSection(tag string, fields []string) error {
+	if _, dup := cfg.Sections[tag]; dup {
+		return fmt.Errorf("duplicated Preset section: %s", tag)
+	}
+	cfg.Sections[tag] = fields
+
+	switch tag {
+	case "NTW":
+		cfg.NTW = &NTWParams{}
+		return cfg.NTW.DecodeParams(fields)
+	case "RPT":
+		if len(fields) != presetRPTCount {
+			return fmt.Errorf("invalid RPT parameters count: %d (expected %d)", len(fields), presetRPTCount)
+		}
+		cfg.RPT = &RPTParams{}
+		return cfg.RPT.DecodeParams(fields[1:])
+	case "EVT":
+		cfg.EVT = &EVTParams{}
+		return cfg.EVT.DecodeParams(fields)
+	case "GSM":
+		cfg.GSM = &GSMParams{}
+		return cfg.GSM.DecodeParams(fields)
+	case "SVC":
+		cfg.SVC = &SVCParams{}
+		return cfg.SVC.DecodeParams(fields)
+	case "ADP":
+		cfg.ADP = &ADPParams{}
+		return cfg.ADP.DecodeParams(fields)
+	case "MSR":
+		cfg.MSR = &MSRParams{}
+		return cfg.MSR.DecodeParams(fields)
+	case "MBV":
+		cfg.MBV = &MBVParams{}
+		return cfg.MBV.DecodeParams(fields)
+	case "NPT":
+		if len(fields) != presetNPTCount {
+			return fmt.Errorf("invalid NPT parameters count: %d (expected %d)", len(fields), presetNPTCount)
+		}
+		// the missing parameters would be sent as zeros by an NPT command, so the section isn't decoded into NPT
+		d, _ := newParamsDecoder("NPT", fields[1:], presetNPTCount-1)
+		(&NPTParams{}).decode(d)
+		return d.err
+	default:
+		cfg.DEV = &DEVParams{}
+		return cfg.DEV.DecodeParams(fields)
+	}
+}
@@ -0,0 +1,31 @@
+package st
+
+import (
+	"time"
+)
+
+// Report is implemented by the reports of every model (except the Alive Report, without a position), giving the
+// fields they have in common.
+type Report interface {
+	Common() CommonReport
+}
+
+// CommonReport holds the fields shared by the reports of every model: the identity of the device, its position, and
+// the readings of the IO and of the power.
+type CommonReport struct {
+	DevID      string
+	Model      Model
+	SwVer      uint16
+	Timestamp  time.Time
+	Cell       string
+	Latitude   float32
+	Longitude  float32
+	Speed      float32
+	Course     float32
+	Satellites uint8
+	GPSFixed   bool
+	Distance   uint32
+	IO         string
+	PowerVolt  float32
+	BackupVolt float32
+}
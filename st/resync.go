@@ -0,0 +1,79 @@
+package st
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// MaxZipLen is the maximum length accepted for a zip frame when looking for the start of a frame.
+const MaxZipLen = 1024
+
+// ErrSkippedBytes is the ParsingError of the messages holding bytes that don't belong to any frame. Those bytes are
+// skipped by the parsers to find the start of the next frame.
+var ErrSkippedBytes = errors.New("unexpected bytes skipped while looking for the next frame")
+
+// ZipAhead returns true if the next bytes of r look like a zip frame: a STX, a sane length and an ETX at the position
+// indicated by the length. Nothing is consumed from r.
+func ZipAhead(r *bufio.Reader) bool {
+	b, err := r.Peek(3)
+	if err != nil || b[0] != STX {
+		return false
+	}
+	length := int(b[1])<<8 | int(b[2])
+	if length == 0 || length > MaxZipLen {
+		return false
+	}
+	b, err = r.Peek(3 + length + 1)
+	if err != nil {
+		return false
+	}
+	return b[len(b)-1] == ETX
+}
+
+// AsciiHdrAhead returns true if the next bytes of r look like the header of an ascii frame, like "ST300STT;" (an
+// 'S', an uppercase letter, 3 digits, 3 uppercase letters and the separator). Nothing is consumed from r.
+func AsciiHdrAhead(r *bufio.Reader) bool {
+	b, err := r.Peek(9)
+	if err != nil || b[0] != 'S' || b[8] != Separator {
+		return false
+	}
+	for i, c := range b[1:8] {
+		switch i {
+		case 1, 2, 3:
+			if c < '0' || c > '9' {
+				return false
+			}
+		default:
+			if c < 'A' || c > 'Z' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SkipToFrame consumes the next byte of r, and then the following ones until the start of a plausible frame (as
+// told by frameAhead) or the end of r. The consumed bytes are returned.
+func SkipToFrame(r *bufio.Reader, frameAhead func() bool) (skipped []byte, err error) {
+	for {
+		c, rErr := r.ReadByte()
+		if rErr != nil {
+			if rErr != io.EOF {
+				err = rErr
+			}
+			return
+		}
+		skipped = append(skipped, c)
+
+		if _, pErr := r.Peek(1); pErr != nil {
+			if pErr != io.EOF {
+				err = pErr
+			}
+			return
+		}
+		if frameAhead() {
+			return
+		}
+	}
+}
@@ -40,6 +40,7 @@ const (
 )
 
 var (
+	// ErrZipUnsupported is not returned anymore, zip frames are decoded by the parsers.
 	ErrZipUnsupported   = errors.New("zip msg unsupported")
 	ErrUnsupportedModel = errors.New("unsupported model")
 
@@ -96,6 +97,20 @@ const (
 	Input3OpenEvt
 )
 
+// FenceType is the kind of geofence of an alert report.
+type FenceType int
+
+const (
+	// The alert isn't related to a geofence group.
+	NoFence FenceType = iota
+
+	// Circular geofence (CGF command).
+	CircleFence
+
+	// Polygonal geofence (PLG command).
+	PolygonFence
+)
+
 // AlertType is the type of and alert report.
 type AlertType int
 
@@ -0,0 +1,362 @@
+package st
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/larixsource/suntech/lexer"
+)
+
+// Zip header bytes, identifying the report type of a zip frame.
+const (
+	ZipSTT = 0x10
+	ZipEMG = 0x11
+	ZipEVT = 0x12
+	ZipALT = 0x13
+	ZipALV = 0x14
+	ZipUEX = 0x15
+)
+
+var (
+	ErrInvalidETX = errors.New("invalid end of zip frame, an ETX was expected")
+	ErrInvalidADC = errors.New("invalid ADC")
+	ErrInvalidIO  = errors.New("invalid IO")
+)
+
+// ZipLen reads the length field of a zip frame (the count of bytes between the length field and the ETX).
+func ZipLen(lex *lexer.Lexer) (length uint16, token lexer.Token, err error) {
+	token, err = lex.NextFixed(2)
+	if err != nil {
+		return
+	}
+	length = uint16(token.Literal[0])<<8 | uint16(token.Literal[1])
+	return
+}
+
+// zipNext reads n bytes from the lexer. A short read is reported as io.ErrUnexpectedEOF, because zip fields are
+// always read from a frame whose length is already known.
+func zipNext(lex *lexer.Lexer, n int) ([]byte, error) {
+	token, err := lex.NextFixed(n)
+	switch err {
+	case nil:
+		return token.Literal, nil
+	case io.EOF:
+		return nil, io.ErrUnexpectedEOF
+	default:
+		return nil, err
+	}
+}
+
+// bcd decodes a packed BCD byte slice to its decimal digits.
+func bcd(b []byte) (string, bool) {
+	digits := make([]byte, 0, 2*len(b))
+	for _, c := range b {
+		hi, lo := c>>4, c&0x0F
+		if hi > 9 || lo > 9 {
+			return "", false
+		}
+		digits = append(digits, '0'+hi, '0'+lo)
+	}
+	return string(digits), true
+}
+
+func beUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// zipFixedPoint decodes a number whose integer part is a binary unsigned integer of intLen bytes, followed by a
+// fractional part of fracLen BCD bytes.
+func zipFixedPoint(lex *lexer.Lexer, intLen, fracLen int, invalid error) (float32, error) {
+	b, err := zipNext(lex, intLen+fracLen)
+	if err != nil {
+		return 0, err
+	}
+	frac, ok := bcd(b[intLen:])
+	if !ok {
+		return 0, invalid
+	}
+	v, err := strconv.ParseFloat(strconv.FormatUint(beUint(b[:intLen]), 10)+"."+frac, 32)
+	if err != nil {
+		return 0, invalid
+	}
+	return float32(v), nil
+}
+
+// zipCoord decodes a coordinate, whose first byte is the signed integer part of the degrees and the next three bytes
+// the BCD decimals.
+func zipCoord(lex *lexer.Lexer, invalid error) (float32, error) {
+	b, err := zipNext(lex, 4)
+	if err != nil {
+		return 0, err
+	}
+	frac, ok := bcd(b[1:])
+	if !ok {
+		return 0, invalid
+	}
+	deg := int8(b[0])
+	sign := ""
+	if deg < 0 {
+		sign = "-"
+		deg = -deg
+	}
+	v, err := strconv.ParseFloat(sign+strconv.Itoa(int(deg))+"."+frac, 32)
+	if err != nil {
+		return 0, invalid
+	}
+	return float32(v), nil
+}
+
+// ZipDevID reads a DevID packed as 9 BCD digits (the last nibble is padding).
+func ZipDevID(lex *lexer.Lexer) (devID string, err error) {
+	b, err := zipNext(lex, 5)
+	if err != nil {
+		return
+	}
+	digits, ok := bcd(b[:4])
+	if !ok || b[4]>>4 > 9 {
+		err = ErrInvalidDevID
+		return
+	}
+	devID = digits + string('0'+b[4]>>4)
+	return
+}
+
+func ZipModel(lex *lexer.Lexer) (model Model, err error) {
+	b, err := zipNext(lex, 1)
+	if err != nil {
+		return
+	}
+	model = Model(b[0])
+	return
+}
+
+func ZipSwVer(lex *lexer.Lexer) (swVer uint16, err error) {
+	b, err := zipNext(lex, 2)
+	if err != nil {
+		return
+	}
+	swVer = uint16(beUint(b))
+	return
+}
+
+// ZipTimestamp reads a timestamp encoded as six binary bytes: year (since 2000), month, day, hour, minutes and
+// seconds.
+func ZipTimestamp(lex *lexer.Lexer) (ts time.Time, err error) {
+	b, err := zipNext(lex, 6)
+	if err != nil {
+		return
+	}
+	if b[1] < 1 || b[1] > 12 || b[2] < 1 || b[2] > 31 {
+		err = ErrInvalidDate
+		return
+	}
+	if b[3] > 23 || b[4] > 59 || b[5] > 59 {
+		err = ErrInvalidTime
+		return
+	}
+	ts = time.Date(2000+int(b[0]), time.Month(b[1]), int(b[2]), int(b[3]), int(b[4]), int(b[5]), 0, time.UTC)
+	return
+}
+
+// ZipCell reads a 2G cell (3 bytes), formatted as the hex string used in the ascii frames.
+func ZipCell(lex *lexer.Lexer) (cell string, err error) {
+	b, err := zipNext(lex, 3)
+	if err != nil {
+		return
+	}
+	cell = fmt.Sprintf("%05X", beUint(b))
+	return
+}
+
+func ZipLat(lex *lexer.Lexer) (lat float32, err error) {
+	return zipCoord(lex, ErrInvalidLat)
+}
+
+func ZipLon(lex *lexer.Lexer) (lon float32, err error) {
+	return zipCoord(lex, ErrInvalidLng)
+}
+
+func ZipSpeed(lex *lexer.Lexer) (speed float32, err error) {
+	return zipFixedPoint(lex, 2, 1, ErrInvalidSpeed)
+}
+
+func ZipCourse(lex *lexer.Lexer) (course float32, err error) {
+	return zipFixedPoint(lex, 2, 1, ErrInvalidCourse)
+}
+
+// ZipSatFix reads the byte that holds the GPS fix status (most significant bit) and the satellites count.
+func ZipSatFix(lex *lexer.Lexer) (satellites uint8, fix bool, err error) {
+	b, err := zipNext(lex, 1)
+	if err != nil {
+		return
+	}
+	fix = b[0]&0x80 != 0
+	satellites = b[0] & 0x7F
+	return
+}
+
+func ZipDistance(lex *lexer.Lexer) (distance uint32, err error) {
+	b, err := zipNext(lex, 4)
+	if err != nil {
+		return
+	}
+	distance = uint32(beUint(b))
+	return
+}
+
+func ZipPowerVolt(lex *lexer.Lexer) (powerVolt float32, err error) {
+	return zipFixedPoint(lex, 1, 1, ErrInvalidPowerVolt)
+}
+
+// ZipIO reads the IO status byte, returning it as the string of bits used in the ascii frames (the first char is
+// the least significant bit).
+func ZipIO(lex *lexer.Lexer, bits int) (ioStatus string, err error) {
+	b, err := zipNext(lex, 1)
+	if err != nil {
+		return
+	}
+	buf := make([]byte, bits)
+	for i := range buf {
+		buf[i] = '0' + (b[0]>>uint(i))&1
+	}
+	ioStatus = string(buf)
+	return
+}
+
+func ZipMode(lex *lexer.Lexer) (mode ModeType, err error) {
+	b, err := zipNext(lex, 1)
+	if err != nil {
+		return
+	}
+	switch ModeType(b[0]) {
+	case ParkingMode, DrivingMode, DistanceMode, AngleMode:
+		mode = ModeType(b[0])
+	default:
+		err = fmt.Errorf("invalid mode value: %v", b[0])
+	}
+	return
+}
+
+func ZipMsgNum(lex *lexer.Lexer) (msgNum uint16, err error) {
+	b, err := zipNext(lex, 2)
+	if err != nil {
+		return
+	}
+	msgNum = uint16(beUint(b))
+	return
+}
+
+func ZipDrivingHourMeter(lex *lexer.Lexer) (hmeter uint32, err error) {
+	b, err := zipNext(lex, 4)
+	if err != nil {
+		return
+	}
+	hmeter = uint32(beUint(b))
+	return
+}
+
+func ZipBackupVolt(lex *lexer.Lexer) (backupVolt float32, err error) {
+	return zipFixedPoint(lex, 1, 1, ErrInvalidPowerVolt)
+}
+
+func ZipBit(lex *lexer.Lexer) (value bool, err error) {
+	b, err := zipNext(lex, 1)
+	if err != nil {
+		return
+	}
+	switch b[0] {
+	case 0:
+		value = false
+	case 1:
+		value = true
+	default:
+		err = fmt.Errorf("invalid bit value: %v", b[0])
+	}
+	return
+}
+
+func ZipEmgID(lex *lexer.Lexer) (emgType EmergencyType, err error) {
+	b, err := zipNext(lex, 1)
+	if err != nil {
+		return
+	}
+	var ok bool
+	if b[0] <= 9 {
+		emgType, ok = emergencyTypeOf('0' + b[0])
+	}
+	if !ok {
+		err = fmt.Errorf("unknown EmgID value: %v", b[0])
+	}
+	return
+}
+
+func ZipEvtID(lex *lexer.Lexer) (evtType EventType, err error) {
+	b, err := zipNext(lex, 1)
+	if err != nil {
+		return
+	}
+	var ok bool
+	if b[0] <= 9 {
+		evtType, ok = eventTypeOf('0' + b[0])
+	}
+	if !ok {
+		err = fmt.Errorf("unknown EvtID value: %v", b[0])
+	}
+	return
+}
+
+func ZipAltID(lex *lexer.Lexer) (altType AlertType, err error) {
+	b, err := zipNext(lex, 1)
+	if err != nil {
+		return
+	}
+	var ok bool
+	if altType, ok = alertTypeOf(strconv.Itoa(int(b[0]))); !ok {
+		err = fmt.Errorf("unknown AltID value: %v", b[0])
+	}
+	return
+}
+
+func ZipADC(lex *lexer.Lexer) (adc float32, err error) {
+	return zipFixedPoint(lex, 1, 1, ErrInvalidADC)
+}
+
+// ZipData reads the length (2 bytes) and the content of a block of external data.
+func ZipData(lex *lexer.Lexer) (length uint16, data []byte, err error) {
+	b, err := zipNext(lex, 2)
+	if err != nil {
+		return
+	}
+	length = uint16(beUint(b))
+	if length == 0 {
+		return
+	}
+	data, err = zipNext(lex, int(length))
+	return
+}
+
+func ZipChecksum(lex *lexer.Lexer) (chk uint8, err error) {
+	b, err := zipNext(lex, 1)
+	if err != nil {
+		return
+	}
+	chk = b[0]
+	return
+}
+
+// ZipUint reads a big endian unsigned integer of n bytes (n <= 8).
+func ZipUint(lex *lexer.Lexer, n int) (v uint64, err error) {
+	b, err := zipNext(lex, n)
+	if err != nil {
+		return
+	}
+	v = beUint(b)
+	return
+}
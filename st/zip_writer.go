@@ -0,0 +1,291 @@
+package st
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ZipWriter builds the body of a zip frame, field by field, using the same encoding read by the Zip* functions.
+// The first error found is kept, and the following writes are ignored.
+type ZipWriter struct {
+	buf bytes.Buffer
+	err error
+}
+
+// Err returns the first error found while writing the fields.
+func (w *ZipWriter) Err() error {
+	return w.err
+}
+
+// Frame returns the complete zip frame (STX, length, header, fields and ETX).
+func (w *ZipWriter) Frame(hdr byte) ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	length := w.buf.Len() + 1
+	if length > MaxZipLen {
+		return nil, fmt.Errorf("zip frame too long: %d", length)
+	}
+	frame := make([]byte, 0, length+4)
+	frame = append(frame, STX, byte(length>>8), byte(length), hdr)
+	frame = append(frame, w.buf.Bytes()...)
+	frame = append(frame, ETX)
+	return frame, nil
+}
+
+func (w *ZipWriter) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// Uint writes v as a big endian unsigned integer of n bytes.
+func (w *ZipWriter) Uint(v uint64, n int) {
+	if w.err != nil {
+		return
+	}
+	if n < 8 && v >= 1<<(8*uint(n)) {
+		w.fail(fmt.Errorf("value %d doesn't fit in %d bytes", v, n))
+		return
+	}
+	for i := n - 1; i >= 0; i-- {
+		w.buf.WriteByte(byte(v >> (8 * uint(i))))
+	}
+}
+
+// bcd writes the given decimal digits as packed BCD (the digits count must be even).
+func (w *ZipWriter) bcd(digits string, invalid error) {
+	if w.err != nil {
+		return
+	}
+	if len(digits)%2 != 0 {
+		w.fail(invalid)
+		return
+	}
+	for i := 0; i < len(digits); i += 2 {
+		hi, lo := digits[i], digits[i+1]
+		if hi < '0' || hi > '9' || lo < '0' || lo > '9' {
+			w.fail(invalid)
+			return
+		}
+		w.buf.WriteByte((hi-'0')<<4 | (lo - '0'))
+	}
+}
+
+// fixedPoint writes v as a binary unsigned integer of intLen bytes, followed by fracLen BCD bytes of decimals.
+func (w *ZipWriter) fixedPoint(v float32, intLen, fracLen int, invalid error) {
+	if w.err != nil {
+		return
+	}
+	if v < 0 {
+		w.fail(invalid)
+		return
+	}
+	sz := strconv.FormatFloat(float64(v), 'f', 2*fracLen, 32)
+	dot := strings.IndexByte(sz, '.')
+	intPart, err := strconv.ParseUint(sz[:dot], 10, 64)
+	if err != nil || (intLen < 8 && intPart >= 1<<(8*uint(intLen))) {
+		w.fail(invalid)
+		return
+	}
+	w.Uint(intPart, intLen)
+	w.bcd(sz[dot+1:], invalid)
+}
+
+// coord writes a coordinate as a signed byte of degrees plus 3 BCD bytes of decimals.
+func (w *ZipWriter) coord(v float32, invalid error) {
+	if w.err != nil {
+		return
+	}
+	sz := strconv.FormatFloat(float64(v), 'f', 6, 32)
+	negative := strings.HasPrefix(sz, "-")
+	sz = strings.TrimPrefix(sz, "-")
+	dot := strings.IndexByte(sz, '.')
+	deg, err := strconv.Atoi(sz[:dot])
+	if err != nil || deg > 127 || (negative && deg == 0) {
+		// the sign is held by the degrees, so (-1, 0) can't be represented
+		w.fail(invalid)
+		return
+	}
+	if negative {
+		deg = -deg
+	}
+	w.buf.WriteByte(byte(int8(deg)))
+	w.bcd(sz[dot+1:], invalid)
+}
+
+// DevID writes a DevID of 9 digits as packed BCD, with a padding nibble at the end.
+func (w *ZipWriter) DevID(devID string) {
+	if len(devID) != 9 {
+		w.fail(ErrInvalidDevID)
+		return
+	}
+	w.bcd(devID+"0", ErrInvalidDevID)
+}
+
+func (w *ZipWriter) Model(model Model) {
+	w.Uint(uint64(model), 1)
+}
+
+func (w *ZipWriter) SwVer(swVer uint16) {
+	w.Uint(uint64(swVer), 2)
+}
+
+func (w *ZipWriter) Timestamp(ts time.Time) {
+	ts = ts.UTC()
+	if ts.Year() < 2000 || ts.Year() > 2255 {
+		w.fail(ErrInvalidDate)
+		return
+	}
+	for _, v := range []int{ts.Year() - 2000, int(ts.Month()), ts.Day(), ts.Hour(), ts.Minute(), ts.Second()} {
+		w.Uint(uint64(v), 1)
+	}
+}
+
+// Cell writes a 2G cell, given as the hex string used in the ascii frames.
+func (w *ZipWriter) Cell(cell string) {
+	v, err := strconv.ParseUint(cell, 16, 24)
+	if err != nil {
+		w.fail(ErrInvalidCell)
+		return
+	}
+	w.Uint(v, 3)
+}
+
+func (w *ZipWriter) Lat(lat float32) {
+	w.coord(lat, ErrInvalidLat)
+}
+
+func (w *ZipWriter) Lon(lon float32) {
+	w.coord(lon, ErrInvalidLng)
+}
+
+func (w *ZipWriter) Speed(speed float32) {
+	w.fixedPoint(speed, 2, 1, ErrInvalidSpeed)
+}
+
+func (w *ZipWriter) Course(course float32) {
+	w.fixedPoint(course, 2, 1, ErrInvalidCourse)
+}
+
+func (w *ZipWriter) SatFix(satellites uint8, fix bool) {
+	if satellites > 0x7F {
+		w.fail(ErrInvalidSatt)
+		return
+	}
+	b := satellites
+	if fix {
+		b |= 0x80
+	}
+	w.Uint(uint64(b), 1)
+}
+
+func (w *ZipWriter) Distance(distance uint32) {
+	w.Uint(uint64(distance), 4)
+}
+
+func (w *ZipWriter) PowerVolt(powerVolt float32) {
+	w.fixedPoint(powerVolt, 1, 1, ErrInvalidPowerVolt)
+}
+
+// IO writes the IO status, given as the string of bits used in the ascii frames (the first char is the least
+// significant bit).
+func (w *ZipWriter) IO(ioStatus string) {
+	if len(ioStatus) > 8 {
+		w.fail(ErrInvalidIO)
+		return
+	}
+	var b byte
+	for i := 0; i < len(ioStatus); i++ {
+		switch ioStatus[i] {
+		case '0':
+		case '1':
+			b |= 1 << uint(i)
+		default:
+			w.fail(ErrInvalidIO)
+			return
+		}
+	}
+	w.Uint(uint64(b), 1)
+}
+
+func (w *ZipWriter) Mode(mode ModeType) {
+	switch mode {
+	case ParkingMode, DrivingMode, DistanceMode, AngleMode:
+		w.Uint(uint64(mode), 1)
+	default:
+		w.fail(ErrInvalidMode)
+	}
+}
+
+func (w *ZipWriter) MsgNum(msgNum uint16) {
+	w.Uint(uint64(msgNum), 2)
+}
+
+func (w *ZipWriter) DrivingHourMeter(hmeter uint32) {
+	w.Uint(uint64(hmeter), 4)
+}
+
+func (w *ZipWriter) BackupVolt(backupVolt float32) {
+	w.fixedPoint(backupVolt, 1, 1, ErrInvalidPowerVolt)
+}
+
+func (w *ZipWriter) Bit(value bool) {
+	if value {
+		w.Uint(1, 1)
+	} else {
+		w.Uint(0, 1)
+	}
+}
+
+func (w *ZipWriter) EmgID(emgType EmergencyType) {
+	if emgType < 0 || emgType > 9 {
+		w.fail(ErrInvalidEmgID)
+		return
+	}
+	if _, ok := emergencyTypeOf('0' + byte(emgType)); !ok {
+		w.fail(ErrInvalidEmgID)
+		return
+	}
+	w.Uint(uint64(emgType), 1)
+}
+
+func (w *ZipWriter) EvtID(evtType EventType) {
+	if evtType < 0 || evtType > 9 {
+		w.fail(ErrInvalidEvtID)
+		return
+	}
+	if _, ok := eventTypeOf('0' + byte(evtType)); !ok {
+		w.fail(ErrInvalidEvtID)
+		return
+	}
+	w.Uint(uint64(evtType), 1)
+}
+
+func (w *ZipWriter) AltID(altType AlertType) {
+	if _, ok := alertTypeOf(strconv.Itoa(int(altType))); !ok {
+		w.fail(ErrInvalidAltID)
+		return
+	}
+	w.Uint(uint64(altType), 1)
+}
+
+func (w *ZipWriter) ADC(adc float32) {
+	w.fixedPoint(adc, 1, 1, ErrInvalidADC)
+}
+
+// Data writes the length (2 bytes) and the content of a block of external data.
+func (w *ZipWriter) Data(data []byte) {
+	w.Uint(uint64(len(data)), 2)
+	if w.err != nil {
+		return
+	}
+	w.buf.Write(data)
+}
+
+func (w *ZipWriter) Checksum(chk uint8) {
+	w.Uint(uint64(chk), 1)
+}
@@ -0,0 +1,37 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300ADP holds the additional connection parameters of a device (ADP command).
+type ST300ADP struct {
+	DevID string
+	SwVer string
+	st.ADPParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (adp *ST300ADP) Command() ([]byte, error) {
+	return st.Command("ST300ADP", adp.DevID, adp.SwVer, &adp.ADPParams)
+}
+
+func parseADP(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = ADPCmd
+
+	adp := &ST300ADP{}
+	msg.ADP = adp
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	adp.Resp, adp.DevID, adp.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &adp.ADPParams)
+}
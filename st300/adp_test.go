@@ -0,0 +1,23 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestST300ADP(t *testing.T) {
+	msg := parseOne(t, "ST300ADP;100850000;02;U;T;2;9000;0;0;0;0;0;0\r")
+	assert.Equal(t, ADPCmd, msg.Type)
+	assert.Equal(t, &ST300ADP{
+		DevID: "100850000",
+		SwVer: "02",
+		ADPParams: st.ADPParams{
+			MainProtocol:   "U",
+			BackupProtocol: "T",
+			Mode:           2,
+			Port:           9000,
+		},
+	}, msg.ADP)
+}
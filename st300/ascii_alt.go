@@ -29,6 +29,12 @@ type AlertReport struct {
 	RealTime         bool
 }
 
+// Common returns the fields of the report shared with the other models.
+func (alt *AlertReport) Common() st.CommonReport {
+	return common(alt.DevID, alt.Model, alt.SwVer, alt.Timestamp, alt.Cell, alt.Latitude, alt.Longitude, alt.Speed,
+		alt.Course, alt.Satellites, alt.GPSFixed, alt.Distance, alt.PowerVolt, alt.IO, alt.BackupVolt)
+}
+
 func parseALTAscii(lex *lexer.Lexer, msg *Msg) {
 	msg.Type = ALTReport
 
@@ -65,8 +71,9 @@ func parseALTAscii(lex *lexer.Lexer, msg *Msg) {
 	alt.SwVer = swVer
 
 	ts, tokens, err := st.AsciiTimestamp(lex)
-	msg.Frame = append(msg.Frame, tokens[0].Literal...)
-	msg.Frame = append(msg.Frame, tokens[1].Literal...)
+	for _, t := range tokens {
+		msg.Frame = append(msg.Frame, t.Literal...)
+	}
 	if err != nil {
 		msg.ParsingError = err
 		return
@@ -177,10 +184,7 @@ func parseALTAscii(lex *lexer.Lexer, msg *Msg) {
 	}
 	alt.BackupVolt = backupVolt
 
-	var unknownTail bool
-	if model != st.ST300 && model != st.ST340 && model != st.ST340LC {
-		unknownTail = true
-	}
+	unknownTail := !knownTail(model)
 
 	realTime, token, err := st.AsciiBit(lex, !unknownTail)
 	msg.Frame = append(msg.Frame, token.Literal...)
@@ -202,3 +206,32 @@ func parseALTAscii(lex *lexer.Lexer, msg *Msg) {
 
 	return
 }
+
+// MarshalASCII returns the ascii frame of the report. Only the models whose reports end at RealTime (ST300, ST340
+// and ST340LC) are supported, because the extra fields of other models aren't kept in the report.
+func (alt *AlertReport) MarshalASCII() ([]byte, error) {
+	if !knownTail(alt.Model) {
+		return nil, st.ErrUnsupportedModel
+	}
+	var w st.AsciiWriter
+	w.Hdr("ST300ALT")
+	w.DevID(alt.DevID)
+	w.Model(alt.Model)
+	w.SwVer(alt.SwVer)
+	w.Timestamp(alt.Timestamp)
+	w.Cell(alt.Cell)
+	w.Lat(alt.Latitude)
+	w.Lon(alt.Longitude)
+	w.Speed(alt.Speed)
+	w.Course(alt.Course)
+	w.Satellites(alt.Satellites)
+	w.Fix(alt.GPSFixed)
+	w.Distance(alt.Distance)
+	w.PowerVolt(alt.PowerVolt)
+	w.IO(alt.IO)
+	w.AltID(alt.AltID)
+	w.DrivingHourMeter(alt.DrivingHourMeter)
+	w.BackupVolt(alt.BackupVolt)
+	w.Bit(alt.RealTime)
+	return w.Frame()
+}
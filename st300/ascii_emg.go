@@ -29,6 +29,12 @@ type EmergencyReport struct {
 	RealTime         bool
 }
 
+// Common returns the fields of the report shared with the other models.
+func (emg *EmergencyReport) Common() st.CommonReport {
+	return common(emg.DevID, emg.Model, emg.SwVer, emg.Timestamp, emg.Cell, emg.Latitude, emg.Longitude, emg.Speed,
+		emg.Course, emg.Satellites, emg.GPSFixed, emg.Distance, emg.PowerVolt, emg.IO, emg.BackupVolt)
+}
+
 func parseEMGAscii(lex *lexer.Lexer, msg *Msg) {
 	msg.Type = EMGReport
 
@@ -65,8 +71,9 @@ func parseEMGAscii(lex *lexer.Lexer, msg *Msg) {
 	emg.SwVer = swVer
 
 	ts, tokens, err := st.AsciiTimestamp(lex)
-	msg.Frame = append(msg.Frame, tokens[0].Literal...)
-	msg.Frame = append(msg.Frame, tokens[1].Literal...)
+	for _, t := range tokens {
+		msg.Frame = append(msg.Frame, t.Literal...)
+	}
 	if err != nil {
 		msg.ParsingError = err
 		return
@@ -177,10 +184,7 @@ func parseEMGAscii(lex *lexer.Lexer, msg *Msg) {
 	}
 	emg.BackupVolt = backupVolt
 
-	var unknownTail bool
-	if model != st.ST300 && model != st.ST340 && model != st.ST340LC {
-		unknownTail = true
-	}
+	unknownTail := !knownTail(model)
 
 	realTime, token, err := st.AsciiBit(lex, !unknownTail)
 	msg.Frame = append(msg.Frame, token.Literal...)
@@ -202,3 +206,32 @@ func parseEMGAscii(lex *lexer.Lexer, msg *Msg) {
 
 	return
 }
+
+// MarshalASCII returns the ascii frame of the report. Only the models whose reports end at RealTime (ST300, ST340
+// and ST340LC) are supported, because the extra fields of other models aren't kept in the report.
+func (emg *EmergencyReport) MarshalASCII() ([]byte, error) {
+	if !knownTail(emg.Model) {
+		return nil, st.ErrUnsupportedModel
+	}
+	var w st.AsciiWriter
+	w.Hdr("ST300EMG")
+	w.DevID(emg.DevID)
+	w.Model(emg.Model)
+	w.SwVer(emg.SwVer)
+	w.Timestamp(emg.Timestamp)
+	w.Cell(emg.Cell)
+	w.Lat(emg.Latitude)
+	w.Lon(emg.Longitude)
+	w.Speed(emg.Speed)
+	w.Course(emg.Course)
+	w.Satellites(emg.Satellites)
+	w.Fix(emg.GPSFixed)
+	w.Distance(emg.Distance)
+	w.PowerVolt(emg.PowerVolt)
+	w.IO(emg.IO)
+	w.EmgID(emg.EmgID)
+	w.DrivingHourMeter(emg.DrivingHourMeter)
+	w.BackupVolt(emg.BackupVolt)
+	w.Bit(emg.RealTime)
+	return w.Frame()
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"bytes"
+	"strconv"
 
 	"github.com/larixsource/suntech/lexer"
 	"github.com/larixsource/suntech/st"
@@ -31,6 +32,12 @@ type EventReport struct {
 	RealTime         bool
 }
 
+// Common returns the fields of the report shared with the other models.
+func (evt *EventReport) Common() st.CommonReport {
+	return common(evt.DevID, evt.Model, evt.SwVer, evt.Timestamp, evt.Cell, evt.Latitude, evt.Longitude, evt.Speed,
+		evt.Course, evt.Satellites, evt.GPSFixed, evt.Distance, evt.PowerVolt, evt.IO, evt.BackupVolt)
+}
+
 func parseEVTAscii(lex *lexer.Lexer, msg *Msg) {
 	msg.Type = EVTReport
 
@@ -47,20 +54,35 @@ func parseEVTAscii(lex *lexer.Lexer, msg *Msg) {
 	case token.OnlyDigits() && len(token.WithoutSuffix()) == 9:
 		evt.DevID = string(token.WithoutSuffix())
 	case bytes.Equal(st.ResLiteral, token.WithoutSuffix()):
-		msg.Type = UnknownMsg
-		msg.ParsingError = ErrUnknownHdr
+		parseEVTResp(lex, msg)
 		return
 	default:
 		msg.ParsingError = st.ErrInvalidDevID
 		return
 	}
 
-	model, token, err := st.AsciiModel(lex)
-	msg.Frame = append(msg.Frame, token.Literal...)
+	model, modelToken, err := st.AsciiModel(lex)
+	msg.Frame = append(msg.Frame, modelToken.Literal...)
 	if err != nil {
 		msg.ParsingError = err
 		return
 	}
+
+	// the SwVer of a report has 3 digits, otherwise it's the first parameter of an EVT command (DevID;Ver;params)
+	token, err = lex.Next(4, st.Separator)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err == nil && len(token.Literal) < 4 {
+		msg.Type = EVTCmd
+		msg.EVT = nil
+		cmd := &ST300EVT{
+			DevID: evt.DevID,
+			SwVer: string(modelToken.WithoutSuffix()),
+		}
+		msg.EVTCmd = cmd
+		parseEVTCmdParams(lex, msg, cmd, token.Literal)
+		return
+	}
+
 	msg.Model = model
 	evt.Model = model
 	if !knownModel(model) {
@@ -68,17 +90,29 @@ func parseEVTAscii(lex *lexer.Lexer, msg *Msg) {
 		return
 	}
 
-	swVer, token, err := st.AsciiSwVer(lex)
-	msg.Frame = append(msg.Frame, token.Literal...)
 	if err != nil {
 		msg.ParsingError = err
 		return
 	}
-	evt.SwVer = swVer
+	if !token.OnlyDigits() {
+		msg.ParsingError = st.ErrInvalidSwVer
+		return
+	}
+	if !token.EndsWith(st.Separator) {
+		msg.ParsingError = st.ErrSeparator
+		return
+	}
+	swVer, err := strconv.ParseUint(string(token.WithoutSuffix()), 10, 16)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	evt.SwVer = uint16(swVer)
 
 	ts, tokens, err := st.AsciiTimestamp(lex)
-	msg.Frame = append(msg.Frame, tokens[0].Literal...)
-	msg.Frame = append(msg.Frame, tokens[1].Literal...)
+	for _, t := range tokens {
+		msg.Frame = append(msg.Frame, t.Literal...)
+	}
 	if err != nil {
 		msg.ParsingError = err
 		return
@@ -189,10 +223,7 @@ func parseEVTAscii(lex *lexer.Lexer, msg *Msg) {
 	}
 	evt.BackupVolt = backupVolt
 
-	var unknownTail bool
-	if model != st.ST300 && model != st.ST340 && model != st.ST340LC {
-		unknownTail = true
-	}
+	unknownTail := !knownTail(model)
 
 	realTime, token, err := st.AsciiBit(lex, !unknownTail)
 	msg.Frame = append(msg.Frame, token.Literal...)
@@ -214,3 +245,32 @@ func parseEVTAscii(lex *lexer.Lexer, msg *Msg) {
 
 	return
 }
+
+// MarshalASCII returns the ascii frame of the report. Only the models whose reports end at RealTime (ST300, ST340
+// and ST340LC) are supported, because the extra fields of other models aren't kept in the report.
+func (evt *EventReport) MarshalASCII() ([]byte, error) {
+	if !knownTail(evt.Model) {
+		return nil, st.ErrUnsupportedModel
+	}
+	var w st.AsciiWriter
+	w.Hdr("ST300EVT")
+	w.DevID(evt.DevID)
+	w.Model(evt.Model)
+	w.SwVer(evt.SwVer)
+	w.Timestamp(evt.Timestamp)
+	w.Cell(evt.Cell)
+	w.Lat(evt.Latitude)
+	w.Lon(evt.Longitude)
+	w.Speed(evt.Speed)
+	w.Course(evt.Course)
+	w.Satellites(evt.Satellites)
+	w.Fix(evt.GPSFixed)
+	w.Distance(evt.Distance)
+	w.PowerVolt(evt.PowerVolt)
+	w.IO(evt.IO)
+	w.EvtID(evt.EvtID)
+	w.DrivingHourMeter(evt.DrivingHourMeter)
+	w.BackupVolt(evt.BackupVolt)
+	w.Bit(evt.RealTime)
+	return w.Frame()
+}
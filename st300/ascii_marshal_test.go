@@ -0,0 +1,147 @@
+package st300
+
+import (
+	"testing"
+	"time"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func marshalASCII(t *testing.T, msg *Msg) ([]byte, error) {
+	switch msg.Type {
+	case STTReport:
+		return msg.STT.MarshalASCII()
+	case EMGReport:
+		return msg.EMG.MarshalASCII()
+	case EVTReport:
+		return msg.EVT.MarshalASCII()
+	case ALTReport:
+		return msg.ALT.MarshalASCII()
+	default:
+		t.Fatalf("unexpected msg type: %v", msg.Type)
+		return nil, nil
+	}
+}
+
+// the coordinates of these frames are exact in a float32, so the frames are regenerated without any change
+func TestMarshalASCII(t *testing.T) {
+	frames := []string{
+		"ST300STT;205150043;02;529;20150716;19:33:30;6d6113;-32.500000;-071.125000;000.039;000.00;10;1;724692;12.89;000000;1;5069;001257;4.2;0\r",
+		"ST300STT;100850000;03;010;20081017;07:41:56;00100;+32.500000;+071.125000;000.012;359.99;9;0;0;15.30;001100;4;0072;000000;4.5;1\r",
+		"ST300EMG;100850000;01;010;20081017;07:41:56;00100;+32.500000;+071.125000;000.012;000.00;9;1;0;15.30;001100;3;000000;4.5;1\r",
+		"ST300EVT;100850000;01;010;20081017;07:41:56;00100;+32.500000;+071.125000;000.012;000.00;9;1;0;15.30;001100;6;000000;4.5;1\r",
+		"ST300ALT;100850000;01;010;20081017;07:41:56;00100;+32.500000;+071.125000;000.012;000.00;9;1;0;15.30;001100;33;000000;4.5;1\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		msg := p.Msg()
+		require.Nil(t, msg.ParsingError, frame)
+
+		marshaled, err := marshalASCII(t, msg)
+		require.Nil(t, err, frame)
+		assert.Equal(t, frame, string(marshaled))
+	}
+}
+
+// coordinates with 6 decimals don't survive a float32, so the frames of devices are compared after parsing them again
+func TestMarshalASCIIRoundTrip(t *testing.T) {
+	frames := []string{
+		"ST300STT;205150043;02;529;20150716;19:33:30;6d6113;-32.644923;-071.424437;000.039;000.00;10;1;724692;12.89;000000;1;5069;001257;4.2;0\r",
+		"ST300STT;100850000;03;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;359.99;9;0;0;15.30;001100;4;0072;000000;4.5;1\r",
+		"ST300EMG;100850000;01;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;001100;3;000000;4.5;1\r",
+		"ST300EVT;100850000;01;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;001100;6;000000;4.5;1\r",
+		"ST300ALT;100850000;01;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;001100;33;000000;4.5;1\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		msg := p.Msg()
+		require.Nil(t, msg.ParsingError, frame)
+
+		marshaled, err := marshalASCII(t, msg)
+		require.Nil(t, err, frame)
+
+		p = ParseBytes(marshaled, ParserOpts{})
+		require.True(t, p.Next())
+		remsg := p.Msg()
+		require.Nil(t, remsg.ParsingError, string(marshaled))
+		assert.Equal(t, marshaled, remsg.Frame)
+
+		// same reports, the frames apart
+		expected, actual := *msg, *remsg
+		expected.Frame, actual.Frame = nil, nil
+		assert.Equal(t, expected, actual)
+
+		remarshaled, err := marshalASCII(t, remsg)
+		require.Nil(t, err)
+		assert.Equal(t, marshaled, remarshaled)
+	}
+}
+
+func TestMarshalASCIIUnknownTail(t *testing.T) {
+	stt := &StatusReport{
+		Hdr:       STTReport,
+		DevID:     "600850802",
+		Model:     st.ST300K,
+		Timestamp: time.Date(2014, 12, 12, 9, 47, 21, 0, time.UTC),
+		Cell:      "04600",
+		IO:        "000000",
+		Mode:      st.DrivingMode,
+	}
+	_, err := stt.MarshalASCII()
+	assert.Equal(t, st.ErrUnsupportedModel, err)
+}
+
+func TestMarshalASCIIErrors(t *testing.T) {
+	valid := func() *StatusReport {
+		return &StatusReport{
+			Hdr:       STTReport,
+			DevID:     "100850000",
+			Model:     st.ST300,
+			Timestamp: time.Date(2008, 10, 17, 7, 41, 56, 0, time.UTC),
+			Cell:      "00100",
+			IO:        "001100",
+			Mode:      st.ParkingMode,
+		}
+	}
+	_, err := valid().MarshalASCII()
+	require.Nil(t, err)
+
+	stt := valid()
+	stt.DevID = "1234"
+	_, err = stt.MarshalASCII()
+	assert.Equal(t, st.ErrInvalidDevID, err)
+
+	stt = valid()
+	stt.Latitude = 100
+	_, err = stt.MarshalASCII()
+	assert.Equal(t, st.ErrInvalidLat, err)
+
+	stt = valid()
+	stt.Cell = "1234567"
+	_, err = stt.MarshalASCII()
+	assert.Equal(t, st.ErrInvalidCell, err)
+
+	stt = valid()
+	stt.Speed = -1
+	_, err = stt.MarshalASCII()
+	assert.Equal(t, st.ErrInvalidSpeed, err)
+
+	stt = valid()
+	stt.IO = "0012"
+	_, err = stt.MarshalASCII()
+	assert.Equal(t, st.ErrInvalidIO, err)
+
+	stt = valid()
+	stt.Mode = 3
+	_, err = stt.MarshalASCII()
+	assert.Equal(t, st.ErrInvalidMode, err)
+
+	stt = valid()
+	stt.MsgNum = 10000
+	_, err = stt.MarshalASCII()
+	assert.Equal(t, st.ErrInvalidMsgNum, err)
+}
@@ -30,6 +30,12 @@ type StatusReport struct {
 	RealTime         bool
 }
 
+// Common returns the fields of the report shared with the other models.
+func (stt *StatusReport) Common() st.CommonReport {
+	return common(stt.DevID, stt.Model, stt.SwVer, stt.Timestamp, stt.Cell, stt.Latitude, stt.Longitude, stt.Speed,
+		stt.Course, stt.Satellites, stt.GPSFixed, stt.Distance, stt.PowerVolt, stt.IO, stt.BackupVolt)
+}
+
 func parseSTTAscii(lex *lexer.Lexer, msg *Msg) {
 	msg.Type = STTReport
 
@@ -65,8 +71,9 @@ func parseSTTAscii(lex *lexer.Lexer, msg *Msg) {
 	msg.STT.SwVer = swVer
 
 	ts, tokens, err := st.AsciiTimestamp(lex)
-	msg.Frame = append(msg.Frame, tokens[0].Literal...)
-	msg.Frame = append(msg.Frame, tokens[1].Literal...)
+	for _, t := range tokens {
+		msg.Frame = append(msg.Frame, t.Literal...)
+	}
 	if err != nil {
 		msg.ParsingError = err
 		return
@@ -185,10 +192,7 @@ func parseSTTAscii(lex *lexer.Lexer, msg *Msg) {
 	}
 	msg.STT.BackupVolt = backupVolt
 
-	var unknownTail bool
-	if model != st.ST300 && model != st.ST340 && model != st.ST340LC {
-		unknownTail = true
-	}
+	unknownTail := !knownTail(model)
 
 	realTime, token, err := st.AsciiBit(lex, !unknownTail)
 	msg.Frame = append(msg.Frame, token.Literal...)
@@ -210,3 +214,33 @@ func parseSTTAscii(lex *lexer.Lexer, msg *Msg) {
 
 	return
 }
+
+// MarshalASCII returns the ascii frame of the report. Only the models whose reports end at RealTime (ST300, ST340
+// and ST340LC) are supported, because the extra fields of other models aren't kept in the report.
+func (stt *StatusReport) MarshalASCII() ([]byte, error) {
+	if !knownTail(stt.Model) {
+		return nil, st.ErrUnsupportedModel
+	}
+	var w st.AsciiWriter
+	w.Hdr("ST300STT")
+	w.DevID(stt.DevID)
+	w.Model(stt.Model)
+	w.SwVer(stt.SwVer)
+	w.Timestamp(stt.Timestamp)
+	w.Cell(stt.Cell)
+	w.Lat(stt.Latitude)
+	w.Lon(stt.Longitude)
+	w.Speed(stt.Speed)
+	w.Course(stt.Course)
+	w.Satellites(stt.Satellites)
+	w.Fix(stt.GPSFixed)
+	w.Distance(stt.Distance)
+	w.PowerVolt(stt.PowerVolt)
+	w.IO(stt.IO)
+	w.Mode(stt.Mode)
+	w.MsgNum(stt.MsgNum)
+	w.DrivingHourMeter(stt.DrivingHourMeter)
+	w.BackupVolt(stt.BackupVolt)
+	w.Bit(stt.RealTime)
+	return w.Frame()
+}
@@ -1,61 +1,24 @@
 package st300
 
 import (
-	"bytes"
-	"strconv"
-
 	"github.com/larixsource/suntech/lexer"
 	"github.com/larixsource/suntech/st"
 )
 
+// ST300CGF holds a circular geofence of a device (CGF command).
 type ST300CGF struct {
-	DevID     string
-	SwVer     string
-	GeoID     int
-	Active    bool
-	Latitude  float32
-	Longitude float32
-	Radius    int
-	In        bool
-	Out       bool
+	DevID string
+	SwVer string
+	st.CGFParams
 
 	// Resp is true when this is a response
 	Resp bool
 }
 
-func (gf *ST300CGF) Command() []byte {
-	var buf bytes.Buffer
-	buf.WriteString("ST300CGF;")
-	buf.WriteString(gf.DevID)
-	buf.WriteByte(';')
-	buf.WriteString(gf.SwVer)
-	buf.WriteByte(';')
-	buf.WriteString(strconv.Itoa(gf.GeoID))
-	buf.WriteByte(';')
-	if gf.Active {
-		buf.WriteString("1")
-	} else {
-		buf.WriteString("0")
-	}
-	buf.WriteByte(';')
-	buf.WriteString(strconv.FormatFloat(float64(gf.Latitude), 'f', 6, 32))
-	buf.WriteByte(';')
-	buf.WriteString(strconv.FormatFloat(float64(gf.Longitude), 'f', 6, 32))
-	buf.WriteByte(';')
-	buf.WriteString(strconv.Itoa(gf.Radius))
-	buf.WriteByte(';')
-	if gf.In {
-		buf.WriteString("1")
-	} else {
-		buf.WriteString("0")
-	}
-	buf.WriteByte(';')
-	if gf.Out {
-		buf.WriteString("1")
-	} else {
-		buf.WriteString("0")
-	}
-	return buf.Bytes()
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (gf *ST300CGF) Command() ([]byte, error) {
+	return st.Command("ST300CGF", gf.DevID, gf.SwVer, &gf.CGFParams)
 }
 
 func parseCGF(lex *lexer.Lexer, msg *Msg) {
@@ -64,35 +27,11 @@ func parseCGF(lex *lexer.Lexer, msg *Msg) {
 	cgf := &ST300CGF{}
 	msg.CGF = cgf
 
-	// Res and DevID, or just DevID
-	isDevID, devID, token, err := st.AsciiDevIDOrRes(lex)
-	msg.Frame = append(msg.Frame, token.Literal...)
-	if err != nil {
-		msg.ParsingError = err
-		return
-	}
-	if isDevID {
-		cgf.DevID = devID
-	} else {
-		cgf.Resp = true
-
-		// get devID
-		devID, token, err := st.AsciiDevID(lex)
-		msg.Frame = append(msg.Frame, token.Literal...)
-		if err != nil {
-			msg.ParsingError = err
-			return
-		}
-		cgf.DevID = devID
-	}
-
-	swVer, token, err := st.AsciiSwVer2(lex)
-	msg.Frame = append(msg.Frame, token.Literal...)
-	if err != nil {
-		msg.ParsingError = err
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
 		return
 	}
-	cgf.SwVer = swVer
+	cgf.Resp, cgf.DevID, cgf.SwVer = resp, devID, swVer
 
 	geoID, token, err := st.AsciiGeoID(lex)
 	msg.Frame = append(msg.Frame, token.Literal...)
@@ -3,23 +3,40 @@ package st300
 import (
 	"testing"
 
+	"github.com/larixsource/suntech/st"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestST300CGF(t *testing.T) {
 	cgf := ST300CGF{
-		DevID:     "100850000",
-		SwVer:     "02",
-		GeoID:     1,
-		Active:    true,
-		Latitude:  37.0,
-		Longitude: 127.0,
-		Radius:    50,
-		In:        true,
-		Out:       true,
+		DevID: "100850000",
+		SwVer: "02",
+		CGFParams: st.CGFParams{
+			GeoID:     1,
+			Active:    true,
+			Latitude:  37.0,
+			Longitude: 127.0,
+			Radius:    50,
+			In:        true,
+			Out:       true,
+		},
 	}
-	assert.Equal(t, []byte("ST300CGF;100850000;02;1;1;37.000000;127.000000;50;1;1"), cgf.Command())
+	cmd, err := cgf.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST300CGF;100850000;02;1;1;+37.000000;+127.000000;50;1;1", string(cmd))
+
+	p := ParseString(string(cmd)+"\r", ParserOpts{})
+	require.True(t, p.Next())
+	msg := p.Msg()
+	require.Nil(t, msg.ParsingError)
+	assert.Equal(t, CGFCmd, msg.Type)
+	assert.Equal(t, &cgf, msg.CGF)
+	assert.False(t, p.Next())
+
+	cgf.Latitude = 91
+	_, err = cgf.Command()
+	assert.IsType(t, &st.ParamError{}, err)
 }
 
 func TestST300CGFRes(t *testing.T) {
@@ -0,0 +1,58 @@
+package st300
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300CMD is a CMD command (like Reset or ReqIMSI), or its response. The response of StatusReq is a Status Report.
+type ST300CMD struct {
+	DevID string
+	SwVer string
+	st.Cmd
+
+	// Result holds the content of a response
+	Result *st.CmdResult
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the value of the action. An empty SwVer is
+// sent as st.CmdVersion.
+func (cmd *ST300CMD) Command() ([]byte, error) {
+	return st.Command("ST300CMD", cmd.DevID, cmd.SwVer, &cmd.Cmd)
+}
+
+func parseCMD(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = CMD
+
+	cmd := &ST300CMD{}
+	msg.CMD = cmd
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	cmd.Resp, cmd.DevID, cmd.SwVer = resp, devID, swVer
+
+	if resp {
+		result, err := st.DecodeCmdResult(fields)
+		if err != nil {
+			msg.ParsingError = err
+			return
+		}
+		cmd.Result = result
+		cmd.Cmd = result.Cmd
+		return
+	}
+
+	if len(fields) != 1 {
+		msg.ParsingError = fmt.Errorf("invalid CMD parameters count: %d (expected 1)", len(fields))
+		return
+	}
+	cmd.Cmd, msg.ParsingError = st.DecodeCmd(strings.TrimSpace(fields[0]))
+}
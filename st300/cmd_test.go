@@ -0,0 +1,83 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCMDCommand(t *testing.T) {
+	cmd := ST300CMD{DevID: "100850000", Cmd: st.Cmd{Action: st.StopCountOdoCmd, Value: "1500"}}
+	frame, err := cmd.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST300CMD;100850000;02;StopCountOdo=1500", string(frame))
+
+	parsed := parseOne(t, string(frame)+"\r").CMD
+	cmd.SwVer = st.CmdVersion
+	assert.Equal(t, &cmd, parsed)
+
+	cmd.Value = ""
+	_, err = cmd.Command()
+	assert.IsType(t, &st.ParamError{}, err)
+}
+
+func TestCMDResponse(t *testing.T) {
+	cmd := parseOne(t, "ST300CMD;Res;100850000;267;ReqDriverID;0100004E160000\r").CMD
+	assert.True(t, cmd.Resp)
+	assert.Equal(t, "267", cmd.SwVer)
+	assert.Equal(t, st.ReqDriverIDCmd, cmd.Action)
+	assert.Equal(t, "0100004E160000", cmd.Value)
+	assert.False(t, cmd.Result.NoData)
+
+	cmd = parseOne(t, "ST300CMD;Res;100850000;267;NoDriverID\r").CMD
+	assert.Equal(t, st.ReqDriverIDCmd, cmd.Action)
+	assert.True(t, cmd.Result.NoData)
+
+	cmd = parseOne(t, "ST300CMD;Res;100850000;267;ReqRPMU;A;12500\r").CMD
+	assert.Equal(t, st.ReqRPMUCmd, cmd.Action)
+	assert.Equal(t, []string{"A", "12500"}, cmd.Result.Params)
+
+	cmd = parseOne(t, "ST300CMD;Res;100850000;010;Disable1;20140213;05:16:03;04f54;+37.479630;+126.886010;000.000;000.00;0;0;3095;13.43;000000;1\r").CMD
+	assert.Equal(t, st.Disable1Cmd, cmd.Action)
+	require.NotNil(t, cmd.Result.Extra)
+	assert.Equal(t, uint32(3095), cmd.Result.Extra.Distance)
+}
+
+func TestCMDResponseExtraInfo(t *testing.T) {
+	actions := map[string]st.CmdAction{
+		"Enable1":  st.Enable1Cmd,
+		"Disable1": st.Disable1Cmd,
+		"Enable2":  st.Enable2Cmd,
+		"Disable2": st.Disable2Cmd,
+	}
+	for name, action := range actions {
+		cmd := parseOne(t, "ST300CMD;Res;100850000;010;"+name+";20140213;05:16:03;04f54;+37.479630;+126.886010;000.000;000.00;0;0;3095;13.43;000000;1\r").CMD
+		assert.Equal(t, action, cmd.Action, name)
+		extra := cmd.Result.Extra
+		require.NotNil(t, extra, name)
+		assert.Equal(t, "04f54", extra.Cell)
+		assert.Equal(t, uint32(3095), extra.Distance)
+		assert.Equal(t, float32(13.43), extra.PowerVolt)
+		assert.Equal(t, st.ParkingMode, extra.Mode)
+	}
+
+	cmd := parseOne(t, "ST300CMD;Res;100850000;010;Enable1NoUse\r").CMD
+	assert.Equal(t, st.Enable1Cmd, cmd.Action)
+	assert.Nil(t, cmd.Result.Extra)
+}
+
+func TestCMDResponseErrors(t *testing.T) {
+	frames := []string{
+		"ST300CMD;Res;100850000;010;Unknown\r",
+		"ST300CMD;Res;100850000;010;Enable1;20140213;05:16:03\r",
+		"ST300CMD;Res;100850000;010;Enable1;20140213;05:16:03;04f54;+37.479630;+126.886010;000.000;000.00;0;0;3095;13.43;000200;1\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		assert.NotNil(t, p.Msg().ParsingError, frame)
+		assert.Equal(t, frame, string(p.Msg().Frame))
+	}
+}
@@ -0,0 +1,209 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type commander interface {
+	Command() ([]byte, error)
+}
+
+func TestCommand(t *testing.T) {
+	testCases := []struct {
+		cmd      commander
+		expected string
+	}{
+		{
+			cmd: &ST300NTW{
+				DevID: "100850000",
+				NTWParams: st.NTWParams{
+					Auth:       "0",
+					APN:        "internet",
+					ServerIP:   "111.111.111.111",
+					ServerPort: 8600,
+				},
+			},
+			expected: "ST300NTW;100850000;02;0;internet;;;111.111.111.111;8600;;;;",
+		},
+		{
+			cmd: &ST300RPT{
+				DevID: "100850000",
+				RPTParams: st.RPTParams{
+					ParkingInterval:   180,
+					DrivingInterval:   120,
+					EmergencyInterval: 60,
+					EmergencyCount:    3,
+				},
+			},
+			expected: "ST300RPT;100850000;02;180;120;60;3;0;0;0;0;0",
+		},
+		{
+			cmd: &ST300EVT{
+				DevID: "100850000",
+				EVTParams: st.EVTParams{
+					Ignition:      1,
+					ParkingTime:   60,
+					InType:        [3]int{3, 2, 2},
+					InChat:        [3]int{30, 20, 20},
+					OutType:       [2]int{1, 0},
+					GPSAntennaCut: 1,
+				},
+			},
+			expected: "ST300EVT;100850000;02;1;60;0;3;2;2;30;20;20;1;0;1;0;0;0;0;0;0;0;0;0;0;0;0",
+		},
+		{
+			cmd:      &ST300GSM{DevID: "100850000"},
+			expected: "ST300GSM;100850000;02;0;;;;;0;;;;;;;",
+		},
+		{
+			cmd: &ST300SVC{
+				DevID: "100850000",
+				SVCParams: st.SVCParams{
+					Parking:           1,
+					ParkingTime:       120,
+					AngleReport:       1,
+					StaticFilter:      1,
+					IgnitionOffReport: 1,
+				},
+			},
+			expected: "ST300SVC;100850000;02;1;120;0;0;0;0;1;1;1;0;0;0;0",
+		},
+		{
+			// the frame of the spec
+			cmd: &ST300MBV{
+				DevID: "100850000",
+				MBVParams: st.MBVParams{
+					Levels: [7]float32{0, 22, 19, 8, 18, 0, 0},
+				},
+			},
+			expected: "ST300MBV;100850000;02;0;22;19;8.00;18.00;0;0",
+		},
+		{
+			cmd: &ST300MSR{
+				DevID: "100852588",
+				MSRParams: st.MSRParams{
+					Interval:     600,
+					Acceleration: 0.04,
+					Deceleration: 0.04,
+					Impact:       0.7,
+				},
+			},
+			expected: "ST300MSR;100852588;02;600;0.04;0.04;0.7",
+		},
+		{
+			cmd: &ST300ADP{
+				DevID: "100850000",
+				ADPParams: st.ADPParams{
+					MainProtocol:   "U",
+					BackupProtocol: "T",
+					Mode:           2,
+					Port:           9000,
+				},
+			},
+			expected: "ST300ADP;100850000;02;U;T;2;9000;0;0;0;0;0;0",
+		},
+		{
+			cmd: &ST300NPT{
+				DevID: "100850000",
+				NPTParams: st.NPTParams{
+					DisconnectTime:     500,
+					ConnectTime:        300,
+					SendCount:          5,
+					SendInterval:       10,
+					SendLimit:          70,
+					BackupSendCount:    5,
+					BackupSendInterval: 10,
+					BackupSendLimit:    70,
+				},
+			},
+			expected: "ST300NPT;100850000;02;0;0;0;0;0;500;300;5;10;70;5;10;70;0",
+		},
+		{
+			cmd: &ST300DPA{
+				DevID: "600850000",
+				DPAParams: st.DPAParams{
+					Mode:         1,
+					Acceleration: 30,
+					Braking:      100,
+					SharpTurn:    70,
+					OverSpeed:    100,
+				},
+			},
+			expected: "ST300DPA;600850000;02;1;0.0;30.0;100.0;70.0;100.0",
+		},
+		{
+			cmd: &ST300LTM{
+				DevID: "100850000",
+				LTMParams: st.LTMParams{
+					Enable: 1,
+					Mode:   2,
+				},
+			},
+			expected: "ST300LTM;100850000;02;1;2;0",
+		},
+	}
+	for _, tc := range testCases {
+		cmd, err := tc.cmd.Command()
+		require.Nil(t, err, tc.expected)
+		assert.Equal(t, tc.expected, string(cmd))
+	}
+}
+
+func TestCommandRoundTrip(t *testing.T) {
+	msg := parseOne(t, "ST300RPT;100850000;02;180;120;60;3;0;0;0;0;0\r")
+	cmd, err := msg.RPT.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST300RPT;100850000;02;180;120;60;3;0;0;0;0;0", string(cmd))
+}
+
+func TestCommandErrors(t *testing.T) {
+	testCases := []struct {
+		cmd commander
+		err error
+	}{
+		{
+			cmd: &ST300RPT{DevID: "1008500"},
+			err: st.ErrInvalidDevID,
+		},
+		{
+			cmd: &ST300RPT{DevID: "100850000", SwVer: "0002"},
+			err: st.ErrInvalidSwVer,
+		},
+		{
+			cmd: &ST300RPT{DevID: "100850000", RPTParams: st.RPTParams{DrivingInterval: -1}},
+			err: &st.ParamError{Cmd: "RPT", Param: "DrivingInterval", Value: -1},
+		},
+		{
+			cmd: &ST300NTW{DevID: "100850000", NTWParams: st.NTWParams{ServerPort: 8600}},
+			err: &st.ParamError{Cmd: "NTW", Param: "ServerIP", Value: ""},
+		},
+		{
+			cmd: &ST300NTW{DevID: "100850000", NTWParams: st.NTWParams{APN: "inter;net", ServerIP: "1.1.1.1", ServerPort: 8600}},
+			err: &st.ParamError{Cmd: "NTW", Param: "APN", Value: "inter;net"},
+		},
+		{
+			cmd: &ST300ADP{DevID: "100850000", ADPParams: st.ADPParams{MainProtocol: "X", BackupProtocol: "T"}},
+			err: &st.ParamError{Cmd: "ADP", Param: "MainProtocol", Value: "X"},
+		},
+		{
+			cmd: &ST300MSR{DevID: "100850000", MSRParams: st.MSRParams{Impact: -0.5}},
+			err: &st.ParamError{Cmd: "MSR", Param: "Impact", Value: float32(-0.5)},
+		},
+	}
+	for _, tc := range testCases {
+		cmd, err := tc.cmd.Command()
+		assert.Nil(t, cmd)
+		assert.Equal(t, tc.err, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ltm := st.LTMParams{Enable: 2}
+	assert.EqualError(t, ltm.Validate(), `invalid LTM parameter Enable: "2"`)
+	ltm.Enable = 1
+	assert.Nil(t, ltm.Validate())
+}
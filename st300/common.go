@@ -2,6 +2,7 @@ package st300
 
 import (
 	"errors"
+	"time"
 
 	"github.com/larixsource/suntech/lexer"
 	"github.com/larixsource/suntech/st"
@@ -9,6 +10,34 @@ import (
 
 var ErrInvalidIO = errors.New("invalid IO")
 
+// common returns the fields of a report shared with the other models. The STT, EMG, EVT and ALT reports have the
+// same fields, in this order.
+func common(devID string, model st.Model, swVer uint16, ts time.Time, cell string, lat, lon, speed, course float32,
+	satellites uint8, gpsFixed bool, distance uint32, powerVolt float32, io string, backupVolt float32) st.CommonReport {
+	return st.CommonReport{
+		DevID:      devID,
+		Model:      model,
+		SwVer:      swVer,
+		Timestamp:  ts,
+		Cell:       cell,
+		Latitude:   lat,
+		Longitude:  lon,
+		Speed:      speed,
+		Course:     course,
+		Satellites: satellites,
+		GPSFixed:   gpsFixed,
+		Distance:   distance,
+		IO:         io,
+		PowerVolt:  powerVolt,
+		BackupVolt: backupVolt,
+	}
+}
+
+// KnownModel tells if the reports of the model are decoded by this package.
+func KnownModel(model st.Model) bool {
+	return knownModel(model)
+}
+
 func knownModel(model st.Model) bool {
 	switch model {
 	case st.ST300, st.ST340, st.ST340LC, st.ST300H, st.ST350, st.ST480, st.ST300A,
@@ -19,6 +48,12 @@ func knownModel(model st.Model) bool {
 	}
 }
 
+// knownTail tells if the fields of the ascii reports of the model end at RealTime. The reports of other models have
+// extra fields that are kept only in the raw frame.
+func knownTail(model st.Model) bool {
+	return model == st.ST300 || model == st.ST340 || model == st.ST340LC
+}
+
 func asciiIO(lex *lexer.Lexer) (ioStatus string, token lexer.Token, err error) {
 	token, err = lex.Next(9, st.Separator)
 	if err != nil {
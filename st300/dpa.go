@@ -0,0 +1,37 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300DPA holds the driver pattern analysis parameters of a device (DPA command).
+type ST300DPA struct {
+	DevID string
+	SwVer string
+	st.DPAParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (dpa *ST300DPA) Command() ([]byte, error) {
+	return st.Command("ST300DPA", dpa.DevID, dpa.SwVer, &dpa.DPAParams)
+}
+
+func parseDPA(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = DPACmd
+
+	dpa := &ST300DPA{}
+	msg.DPA = dpa
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	dpa.Resp, dpa.DevID, dpa.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &dpa.DPAParams)
+}
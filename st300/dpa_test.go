@@ -0,0 +1,35 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDPA(t *testing.T) {
+	msg := parseOne(t, "ST300DPA;600850000;02;1;0.0;30.0;100.0;70.0;100.0\r")
+	assert.Equal(t, DPACmd, msg.Type)
+	assert.Equal(t, &ST300DPA{
+		DevID: "600850000",
+		SwVer: "02",
+		DPAParams: st.DPAParams{
+			Mode:         1,
+			Acceleration: 30,
+			Braking:      100,
+			SharpTurn:    70,
+			OverSpeed:    100,
+		},
+	}, msg.DPA)
+}
+
+func TestLTM(t *testing.T) {
+	msg := parseOne(t, "ST300LTM;Res;100850000;010;1;2;0\r")
+	assert.Equal(t, LTMCmd, msg.Type)
+	assert.Equal(t, &ST300LTM{
+		DevID:     "100850000",
+		SwVer:     "010",
+		LTMParams: st.LTMParams{Enable: 1, Mode: 2},
+		Resp:      true,
+	}, msg.LTM)
+}
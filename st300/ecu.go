@@ -0,0 +1,96 @@
+package st300
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ECUParamsCount is the count of parameters of the ECU command.
+const ECUParamsCount = 8
+
+// ECUParams are the engine thresholds of the ECU command (CAN-bus models). Their meaning isn't documented by the spec,
+// so they're kept in the order of the protocol.
+type ECUParams struct {
+	Params [ECUParamsCount]float32
+}
+
+func (p *ECUParams) EncodeParams(w *st.AsciiWriter) {
+	for _, v := range p.Params {
+		w.Field(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	}
+}
+
+func (p *ECUParams) DecodeParams(fields []string) error {
+	if len(fields) != ECUParamsCount {
+		return fmt.Errorf("invalid ECU parameters count: %d (expected %d)", len(fields), ECUParamsCount)
+	}
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 32)
+		if err != nil {
+			return &st.ParamError{Cmd: "ECU", Param: "Params", Value: f}
+		}
+		p.Params[i] = float32(v)
+	}
+	return nil
+}
+
+func (p *ECUParams) Validate() error {
+	for _, v := range p.Params {
+		if v < 0 || v > 99999 || math.IsNaN(float64(v)) {
+			return &st.ParamError{Cmd: "ECU", Param: "Params", Value: v}
+		}
+	}
+	return nil
+}
+
+// ST300ECU holds the engine thresholds of a device (ECU command).
+type ST300ECU struct {
+	DevID string
+	SwVer string
+	ECUParams
+
+	// Error is the code sent by a response instead of the parameters, when the device couldn't set them (like CE)
+	Error string
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (ecu *ST300ECU) Command() ([]byte, error) {
+	if err := ecu.Validate(); err != nil {
+		return nil, err
+	}
+	return st.Command("ST300ECU", ecu.DevID, ecu.SwVer, &ecu.ECUParams)
+}
+
+func parseECU(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = ECUCmd
+
+	ecu := &ST300ECU{}
+	msg.ECU = ecu
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	ecu.Resp, ecu.DevID, ecu.SwVer = resp, devID, swVer
+	ecu.Error, msg.ParsingError = decodeECUResult(resp, &ecu.ECUParams, fields)
+}
+
+// decodeECUResult decodes the parameters of an ECU command, or of the response of ECU or GES, which could have an
+// error code instead.
+func decodeECUResult(resp bool, params *ECUParams, fields []string) (string, error) {
+	if resp && len(fields) == 1 {
+		if code := strings.TrimSpace(fields[0]); code != "" && strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+			return code, nil
+		}
+	}
+	return "", params.DecodeParams(fields)
+}
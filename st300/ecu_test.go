@@ -0,0 +1,85 @@
+package st300
+
+import (
+	"testing"
+	"time"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECU(t *testing.T) {
+	ecu := ST300ECU{
+		DevID: "100850000",
+		ECUParams: ECUParams{
+			Params: [ECUParamsCount]float32{1, 120, 100, 200, 100, 8000, 14.3, 0},
+		},
+	}
+	b, err := ecu.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST300ECU;100850000;02;1;120;100;200;100;8000;14.3;0", string(b))
+
+	msg := parseOne(t, "ST300ECU;Res;850000;02;1;120;100;200;100;8000;14.3;0\r")
+	assert.Equal(t, ECUCmd, msg.Type)
+	ecu.DevID, ecu.SwVer, ecu.Resp = "850000", "02", true
+	assert.Equal(t, &ecu, msg.ECU)
+
+	msg = parseOne(t, "ST300ECU;Res;850000;02;CE\r")
+	assert.Equal(t, "CE", msg.ECU.Error)
+
+	ecu.Params[0] = -1
+	_, err = ecu.Command()
+	assert.IsType(t, &st.ParamError{}, err)
+}
+
+func TestGES(t *testing.T) {
+	ges := ST300GES{DevID: "100850000"}
+	b, err := ges.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST300GES;100850000;02", string(b))
+
+	msg := parseOne(t, "ST300GES;850000;02\r")
+	assert.Equal(t, GESCmd, msg.Type)
+	assert.False(t, msg.GES.Resp)
+
+	msg = parseOne(t, "ST300GES;Res;850000;02;1;120;100;200;100;8000;14.3;0\r")
+	assert.True(t, msg.GES.Resp)
+	assert.Equal(t, [ECUParamsCount]float32{1, 120, 100, 200, 100, 8000, 14.3, 0}, msg.GES.Params)
+}
+
+func TestGED(t *testing.T) {
+	ged := ST300GED{DevID: "100850000"}
+	b, err := ged.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST300GED;100850000;02", string(b))
+
+	msg := parseOne(t, "ST300GED;Res;850000;010;20080101;00:02:37;00000;+36.479528;+127.885968;000.000;000.00;0;0;0;11.93;000000;0;181312.00;0.00;0.00;60;0;40.00;17;0;0.00;0.00\r")
+	assert.Equal(t, GEDCmd, msg.Type)
+	data := msg.GED.Data
+	require.NotNil(t, data)
+	assert.Equal(t, time.Date(2008, 1, 1, 0, 2, 37, 0, time.UTC), data.Timestamp)
+	assert.Equal(t, "00000", data.Cell)
+	assert.InDelta(t, 36.479528, data.Latitude, 0.00001)
+	assert.InDelta(t, 127.885968, data.Longitude, 0.00001)
+	assert.False(t, data.GPSFixed)
+	assert.Equal(t, float32(11.93), data.PowerVolt)
+	assert.Equal(t, "000000", data.IO)
+	assert.Equal(t, [GEDEngineCount]string{"0", "181312.00", "0.00", "0.00", "60", "0", "40.00", "17", "0", "0.00", "0.00"}, data.Engine)
+}
+
+func TestEngineErrors(t *testing.T) {
+	frames := []string{
+		"ST300ECU;850000;02;1;120;100\r",
+		"ST300ECU;850000;02;1;120;100;200;100;A;14.3;0\r",
+		"ST300GES;850000;02;1\r",
+		"ST300GED;Res;850000;010;20080101;00:02:37;00000\r",
+		"ST300GED;Res;850000;010;20080101;00:02:37;00000;+36.479528;+127.885968;000.000;000.00;0;0;0;11.93;000200;0;181312.00;0.00;0.00;60;0;40.00;17;0;0.00;0.00\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		assert.NotNil(t, p.Msg().ParsingError, frame)
+		assert.Equal(t, frame, string(p.Msg().Frame))
+	}
+}
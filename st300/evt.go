@@ -0,0 +1,61 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300EVT holds the event parameters of a device (EVT command, not to be confused with the EVT report).
+type ST300EVT struct {
+	DevID string
+	SwVer string
+	st.EVTParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (evt *ST300EVT) Command() ([]byte, error) {
+	return st.Command("ST300EVT", evt.DevID, evt.SwVer, &evt.EVTParams)
+}
+
+// parseEVTResp parses the response of an EVT command, after the "Res;" field.
+func parseEVTResp(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = EVTCmd
+	msg.EVT = nil
+
+	cmd := &ST300EVT{
+		Resp: true,
+	}
+	msg.EVTCmd = cmd
+
+	devID, token, err := st.AsciiDevID(lex)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmd.DevID = devID
+
+	swVer, token, err := st.AsciiSwVer2(lex)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmd.SwVer = swVer
+
+	parseEVTCmdParams(lex, msg, cmd, nil)
+}
+
+// parseEVTCmdParams reads the parameters of the EVT command. The first parameter could be already read (as it's
+// needed to tell an EVT command from an EVT report).
+func parseEVTCmdParams(lex *lexer.Lexer, msg *Msg, evt *ST300EVT, first []byte) {
+	b, ok := asciiParams(lex, msg)
+	if !ok {
+		return
+	}
+	msg.ParsingError = evt.DecodeParams(st.SplitParams(append(first, b...)))
+}
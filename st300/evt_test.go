@@ -0,0 +1,93 @@
+package st300
+
+import (
+	"io"
+	"testing"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestST300EVTCmd(t *testing.T) {
+	expected := &ST300EVT{
+		DevID: "100850000",
+		SwVer: "02",
+		EVTParams: st.EVTParams{
+			Ignition:      1,
+			ParkingTime:   60,
+			InType:        [3]int{3, 2, 2},
+			InChat:        [3]int{30, 20, 20},
+			OutType:       [2]int{1, 0},
+			GPSAntennaCut: 1,
+		},
+	}
+	msg := parseOne(t, "ST300EVT;100850000;02;1;60;0;3;2;2;30;20;20;1;0;1;0;0;0;0;0;0;0;0;0;0;0;0\r")
+	assert.Equal(t, EVTCmd, msg.Type)
+	assert.Nil(t, msg.EVT)
+	assert.Equal(t, expected, msg.EVTCmd)
+
+	expected.SwVer = "010"
+	expected.Resp = true
+	msg = parseOne(t, "ST300EVT;Res;100850000;010;1;60;0;3;2;2;30;20;20;1;0;1;0;0;0;0;0;0;0;0;0;0;0;0\r")
+	assert.Equal(t, EVTCmd, msg.Type)
+	assert.Nil(t, msg.EVT)
+	assert.Equal(t, expected, msg.EVTCmd)
+}
+
+func TestST300EVTCmdAndReport(t *testing.T) {
+	frames := "ST300EVT;100850000;02;1;60;0;3;2;2;30;20;20;1;0;1;0;0;0;0;0;0;0;0;0;0;0;0\r" +
+		"ST300EVT;100850001;02;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;001100;1;0;4.5;1\r"
+	p := ParseString(frames, ParserOpts{})
+
+	assert.True(t, p.Next())
+	assert.Nil(t, p.Msg().ParsingError)
+	assert.Equal(t, EVTCmd, p.Msg().Type)
+
+	assert.True(t, p.Next())
+	assert.Nil(t, p.Msg().ParsingError)
+	assert.Equal(t, EVTReport, p.Msg().Type)
+	assert.Nil(t, p.Msg().EVTCmd)
+	assert.EqualValues(t, 10, p.Msg().EVT.SwVer)
+
+	assert.False(t, p.Next())
+	assert.Nil(t, p.Error())
+}
+
+// TestEVTReportOrCommand checks the field after the model (or the Ver of a command) of the EVT frames: the SwVer of
+// a report has 3 digits, anything shorter is the first parameter of a command.
+func TestEVTReportOrCommand(t *testing.T) {
+	const (
+		params = "60;0;3;2;2;30;20;20;1;0;1;0;0;0;0;0;0;0;0;0;0;0;0\r"
+		report = "20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;001100;1;0;4.5;1\r"
+	)
+	tests := []struct {
+		frame string
+		typ   MsgType
+		err   error
+	}{
+		{frame: "ST300EVT;100850000;02;1;" + params, typ: EVTCmd},
+		// an empty first parameter
+		{frame: "ST300EVT;100850000;02;;" + params, typ: EVTCmd},
+		// the Ver of a command isn't checked as a model
+		{frame: "ST300EVT;100850000;99;1;" + params, typ: EVTCmd},
+		{frame: "ST300EVT;100850000;02;10;" + params, typ: EVTCmd},
+		{frame: "ST300EVT;100850000;01;010;" + report, typ: EVTReport},
+		{frame: "ST300EVT;100850000;99;010;" + report, typ: EVTReport, err: st.ErrUnsupportedModel},
+		{frame: "ST300EVT;100850000;01;01A;" + report, typ: EVTReport, err: st.ErrInvalidSwVer},
+		{frame: "ST300EVT;100850000;01;0100;" + report, typ: EVTReport, err: lexer.ErrTokenTooLong},
+		// a truncated field is taken as the SwVer of a report
+		{frame: "ST300EVT;100850000;02;1\r", typ: EVTReport, err: io.EOF},
+		{frame: "ST300EVT;100850000;02;", typ: EVTReport, err: io.EOF},
+	}
+	for _, test := range tests {
+		p := ParseString(test.frame, ParserOpts{})
+		require.True(t, p.Next(), test.frame)
+		msg := p.Msg()
+		assert.Equal(t, test.typ, msg.Type, test.frame)
+		assert.Equal(t, test.err, msg.ParsingError, test.frame)
+		assert.Equal(t, test.typ == EVTCmd, msg.EVTCmd != nil, test.frame)
+		assert.Equal(t, test.typ == EVTReport, msg.EVT != nil, test.frame)
+	}
+}
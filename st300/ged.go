@@ -0,0 +1,68 @@
+package st300
+
+import (
+	"fmt"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// GEDEngineCount is the count of engine readings of a GED response.
+const GEDEngineCount = 11
+
+// EngineData is the content of a GED response: the position of the device and the readings of the engine.
+type EngineData struct {
+	st.Position
+
+	// Engine holds the readings of the engine, in the order of the protocol (the spec doesn't document them)
+	Engine [GEDEngineCount]string
+}
+
+// ST300GED requests the engine data of a device (GED command), returned by the response.
+type ST300GED struct {
+	DevID string
+	SwVer string
+
+	// Data is the content of a response
+	Data *EngineData
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR). An empty SwVer is sent as st.CmdVersion.
+func (ged *ST300GED) Command() ([]byte, error) {
+	return st.Command("ST300GED", ged.DevID, ged.SwVer, st.ParamsFunc(func(*st.AsciiWriter) {}))
+}
+
+func parseGED(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = GEDCmd
+
+	ged := &ST300GED{}
+	msg.GED = ged
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	ged.Resp, ged.DevID, ged.SwVer = resp, devID, swVer
+	if !resp {
+		msg.ParsingError = noParams("GED", fields)
+		return
+	}
+
+	const count = st.PositionFieldsCount + GEDEngineCount
+	if len(fields) != count {
+		msg.ParsingError = fmt.Errorf("invalid GED parameters count: %d (expected %d)", len(fields), count)
+		return
+	}
+	pos, err := st.DecodePosition(fields[:st.PositionFieldsCount])
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	ged.Data = &EngineData{
+		Position: *pos,
+	}
+	copy(ged.Data.Engine[:], fields[st.PositionFieldsCount:])
+}
@@ -0,0 +1,55 @@
+package st300
+
+import (
+	"fmt"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300GES requests the engine thresholds of a device (GES command). The response has the parameters of the ECU
+// command.
+type ST300GES struct {
+	DevID string
+	SwVer string
+
+	// ECUParams are the thresholds of a response
+	ECUParams
+
+	// Error is the code sent by a response instead of the parameters (like CE)
+	Error string
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR). An empty SwVer is sent as st.CmdVersion.
+func (ges *ST300GES) Command() ([]byte, error) {
+	return st.Command("ST300GES", ges.DevID, ges.SwVer, st.ParamsFunc(func(*st.AsciiWriter) {}))
+}
+
+func parseGES(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = GESCmd
+
+	ges := &ST300GES{}
+	msg.GES = ges
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	ges.Resp, ges.DevID, ges.SwVer = resp, devID, swVer
+	if !resp {
+		msg.ParsingError = noParams("GES", fields)
+		return
+	}
+	ges.Error, msg.ParsingError = decodeECUResult(resp, &ges.ECUParams, fields)
+}
+
+// noParams checks that a command doesn't have parameters.
+func noParams(cmd string, fields []string) error {
+	if len(fields) != 0 {
+		return fmt.Errorf("invalid %s parameters count: %d (expected 0)", cmd, len(fields))
+	}
+	return nil
+}
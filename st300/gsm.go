@@ -0,0 +1,37 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300GSM holds the GSM parameters of a device (GSM command).
+type ST300GSM struct {
+	DevID string
+	SwVer string
+	st.GSMParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (gsm *ST300GSM) Command() ([]byte, error) {
+	return st.Command("ST300GSM", gsm.DevID, gsm.SwVer, &gsm.GSMParams)
+}
+
+func parseGSM(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = GSMCmd
+
+	gsm := &ST300GSM{}
+	msg.GSM = gsm
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	gsm.Resp, gsm.DevID, gsm.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &gsm.GSMParams)
+}
@@ -0,0 +1,23 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestST300GSM(t *testing.T) {
+	msg := parseOne(t, "ST300GSM;Res;100850000;010;1;+5691111111;;;;0;+5692222222;;;;;;\r")
+	assert.Equal(t, GSMCmd, msg.Type)
+	assert.Equal(t, &ST300GSM{
+		DevID: "100850000",
+		SwVer: "010",
+		GSMParams: st.GSMParams{
+			SMSMode: 1,
+			SMSNo:   [4]string{"+5691111111"},
+			CallNo:  [7]string{"+5692222222"},
+		},
+		Resp: true,
+	}, msg.GSM)
+}
@@ -0,0 +1,42 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300HAD adds driver tags to a device (HAD command, ST300H models), up to 20 per frame.
+type ST300HAD struct {
+	DevID string
+	SwVer string
+	Tags  []DriverTag
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), ending with a separator, like the spec. An empty SwVer
+// is sent as st.CmdVersion.
+func (had *ST300HAD) Command() ([]byte, error) {
+	if err := checkTags("HAD", had.Tags); err != nil {
+		return nil, err
+	}
+	return st.Command("ST300HAD", had.DevID, had.SwVer, st.ParamsFunc(func(w *st.AsciiWriter) {
+		writeTags(w, had.Tags)
+		w.Field("")
+	}))
+}
+
+func parseHAD(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = HADCmd
+
+	had := &ST300HAD{}
+	msg.HAD = had
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	had.Resp, had.DevID, had.SwVer = resp, devID, swVer
+	had.Tags, msg.ParsingError = decodeTags("HAD", fields)
+}
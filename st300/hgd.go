@@ -0,0 +1,76 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+)
+
+// ST300HGD requests the driver tags saved in a device, from an index (HGD command, ST300H models). The response has
+// the tags, split in frames of up to 20 tags (see AssembleTags).
+type ST300HGD struct {
+	DevID string
+	SwVer string
+
+	// Index is the index requested by the command
+	Index int
+
+	// Tags are the tags of a response
+	Tags []DriverTag
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR). An empty SwVer is sent as st.CmdVersion.
+func (hgd *ST300HGD) Command() ([]byte, error) {
+	return tagIndexCommand("HGD", hgd.DevID, hgd.SwVer, hgd.Index)
+}
+
+// More tells if more frames of the response follow this one (it has the maximum count of tags of a frame).
+func (hgd *ST300HGD) More() bool {
+	return hgd.Resp && len(hgd.Tags) == maxPageTags
+}
+
+// AssembleTags joins the tags of the frames of a HGD response, checking that every frame but the last one is full
+// and that the indexes are in order.
+func AssembleTags(pages []*ST300HGD) ([]DriverTag, error) {
+	if len(pages) == 0 {
+		return nil, ErrTagIncomplete
+	}
+	var tags []DriverTag
+	for i, page := range pages {
+		if !page.Resp || len(page.Tags) == 0 {
+			return nil, ErrTagPages
+		}
+		if more := i < len(pages)-1; more != page.More() {
+			if more {
+				return nil, ErrTagPages
+			}
+			return nil, ErrTagIncomplete
+		}
+		for _, tag := range page.Tags {
+			if len(tags) > 0 && tag.Index <= tags[len(tags)-1].Index {
+				return nil, ErrTagPages
+			}
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func parseHGD(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = HGDCmd
+
+	hgd := &ST300HGD{}
+	msg.HGD = hgd
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	hgd.Resp, hgd.DevID, hgd.SwVer = resp, devID, swVer
+	if resp {
+		hgd.Tags, msg.ParsingError = decodeTags("HGD", fields)
+		return
+	}
+	hgd.Index, msg.ParsingError = decodeTagIndexParam("HGD", fields)
+}
@@ -0,0 +1,55 @@
+package st300
+
+import (
+	"fmt"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300HRD removes the driver tag saved at an index (HRD command, ST300H models).
+type ST300HRD struct {
+	DevID string
+	SwVer string
+	Index int
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR). An empty SwVer is sent as st.CmdVersion.
+func (hrd *ST300HRD) Command() ([]byte, error) {
+	return tagIndexCommand("HRD", hrd.DevID, hrd.SwVer, hrd.Index)
+}
+
+func parseHRD(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = HRDCmd
+
+	hrd := &ST300HRD{}
+	msg.HRD = hrd
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	hrd.Resp, hrd.DevID, hrd.SwVer = resp, devID, swVer
+	hrd.Index, msg.ParsingError = decodeTagIndexParam("HRD", fields)
+}
+
+// tagIndexCommand builds the frame of a command with the index of a tag as its only parameter.
+func tagIndexCommand(cmd, devID, swVer string, index int) ([]byte, error) {
+	if err := checkTagIndex(cmd, index); err != nil {
+		return nil, err
+	}
+	return st.Command("ST300"+cmd, devID, swVer, st.ParamsFunc(func(w *st.AsciiWriter) {
+		w.Field(fmt.Sprintf("%03d", index))
+	}))
+}
+
+// decodeTagIndexParam decodes the parameters of a command with the index of a tag as its only parameter.
+func decodeTagIndexParam(cmd string, fields []string) (int, error) {
+	if len(fields) != 1 {
+		return 0, fmt.Errorf("invalid %s parameters count: %d (expected 1)", cmd, len(fields))
+	}
+	return decodeTagIndex(cmd, fields[0])
+}
@@ -0,0 +1,114 @@
+package st300
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// HTPParamsCount is the count of parameters of the HTP command.
+const HTPParamsCount = 15
+
+// ST300HTP holds the driver ID parameters of a device (HTP command, ST300H models).
+type ST300HTP struct {
+	DevID string
+	SwVer string
+
+	// the parameters, in the order of the protocol
+	Enable     string
+	Mode       string
+	ReaderID   string
+	TagLength  string
+	Timeout    string
+	AlarmTime  string
+	LogoutTime string
+	Buzzer     string
+	MasterTag  string
+	Password   string
+	Output     string
+	OutputTime string
+	BeepTime   string
+	AutoLogout string
+	Option     string
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR). An empty SwVer is sent as st.CmdVersion.
+func (htp *ST300HTP) Command() ([]byte, error) {
+	fields := htp.fields()
+	for i, p := range fields {
+		if strings.ContainsAny(*p, ";\r\n") {
+			return nil, &st.ParamError{Cmd: "HTP", Param: htpNames[i], Value: *p}
+		}
+	}
+	return st.Command("ST300HTP", htp.DevID, htp.SwVer, st.ParamsFunc(func(w *st.AsciiWriter) {
+		for _, p := range fields {
+			w.Field(*p)
+		}
+	}))
+}
+
+// htpNames are the names of the HTP parameters, in the order of the protocol.
+var htpNames = [HTPParamsCount]string{
+	"Enable",
+	"Mode",
+	"ReaderID",
+	"TagLength",
+	"Timeout",
+	"AlarmTime",
+	"LogoutTime",
+	"Buzzer",
+	"MasterTag",
+	"Password",
+	"Output",
+	"OutputTime",
+	"BeepTime",
+	"AutoLogout",
+	"Option",
+}
+
+// fields returns the parameters, in the order of the protocol.
+func (htp *ST300HTP) fields() [HTPParamsCount]*string {
+	return [HTPParamsCount]*string{
+		&htp.Enable,
+		&htp.Mode,
+		&htp.ReaderID,
+		&htp.TagLength,
+		&htp.Timeout,
+		&htp.AlarmTime,
+		&htp.LogoutTime,
+		&htp.Buzzer,
+		&htp.MasterTag,
+		&htp.Password,
+		&htp.Output,
+		&htp.OutputTime,
+		&htp.BeepTime,
+		&htp.AutoLogout,
+		&htp.Option,
+	}
+}
+
+func parseHTP(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = HTPCmd
+
+	htp := &ST300HTP{}
+	msg.HTP = htp
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	htp.Resp, htp.DevID, htp.SwVer = resp, devID, swVer
+
+	if len(fields) != HTPParamsCount {
+		msg.ParsingError = fmt.Errorf("invalid HTP parameters count: %d (expected %d)", len(fields), HTPParamsCount)
+		return
+	}
+	for i, p := range htp.fields() {
+		*p = fields[i]
+	}
+}
@@ -0,0 +1,37 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300LTM holds the LTM parameters of a device.
+type ST300LTM struct {
+	DevID string
+	SwVer string
+	st.LTMParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (ltm *ST300LTM) Command() ([]byte, error) {
+	return st.Command("ST300LTM", ltm.DevID, ltm.SwVer, &ltm.LTMParams)
+}
+
+func parseLTM(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = LTMCmd
+
+	ltm := &ST300LTM{}
+	msg.LTM = ltm
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	ltm.Resp, ltm.DevID, ltm.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &ltm.LTMParams)
+}
@@ -0,0 +1,37 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300MBV holds the main battery voltage parameters of a device (MBV command).
+type ST300MBV struct {
+	DevID string
+	SwVer string
+	st.MBVParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (mbv *ST300MBV) Command() ([]byte, error) {
+	return st.Command("ST300MBV", mbv.DevID, mbv.SwVer, &mbv.MBVParams)
+}
+
+func parseMBV(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = MBVCmd
+
+	mbv := &ST300MBV{}
+	msg.MBV = mbv
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	mbv.Resp, mbv.DevID, mbv.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &mbv.MBVParams)
+}
@@ -0,0 +1,21 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestST300MBV(t *testing.T) {
+	msg := parseOne(t, "ST300MBV;Res;100850000;122;0;22;19;8.00;18.00;0;0\r")
+	assert.Equal(t, MBVCmd, msg.Type)
+	assert.Equal(t, &ST300MBV{
+		DevID: "100850000",
+		SwVer: "122",
+		MBVParams: st.MBVParams{
+			Levels: [7]float32{0, 22, 19, 8, 18, 0, 0},
+		},
+		Resp: true,
+	}, msg.MBV)
+}
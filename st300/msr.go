@@ -0,0 +1,37 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300MSR holds the motion sensor parameters of a device (MSR command).
+type ST300MSR struct {
+	DevID string
+	SwVer string
+	st.MSRParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (msr *ST300MSR) Command() ([]byte, error) {
+	return st.Command("ST300MSR", msr.DevID, msr.SwVer, &msr.MSRParams)
+}
+
+func parseMSR(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = MSRCmd
+
+	msr := &ST300MSR{}
+	msg.MSR = msr
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	msr.Resp, msr.DevID, msr.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &msr.MSRParams)
+}
@@ -0,0 +1,24 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestST300MSR(t *testing.T) {
+	msg := parseOne(t, "ST300MSR;Res;100852588;128;600;0.04;0.04;0.70\r")
+	assert.Equal(t, MSRCmd, msg.Type)
+	assert.Equal(t, &ST300MSR{
+		DevID: "100852588",
+		SwVer: "128",
+		MSRParams: st.MSRParams{
+			Interval:     600,
+			Acceleration: 0.04,
+			Deceleration: 0.04,
+			Impact:       0.7,
+		},
+		Resp: true,
+	}, msg.MSR)
+}
@@ -0,0 +1,35 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestST300NPT(t *testing.T) {
+	expected := &ST300NPT{
+		DevID: "100850000",
+		SwVer: "02",
+		NPTParams: st.NPTParams{
+			DisconnectTime:     500,
+			ConnectTime:        300,
+			SendCount:          5,
+			SendInterval:       10,
+			SendLimit:          70,
+			BackupSendCount:    5,
+			BackupSendInterval: 10,
+			BackupSendLimit:    70,
+		},
+	}
+	msg := parseOne(t, "ST300NPT;100850000;02;0;0;0;0;0;500;300;5;10;70;5;10;70;0\r")
+	assert.Equal(t, NPTCmd, msg.Type)
+	assert.Equal(t, expected, msg.NPT)
+
+	// the response repeats the version of the command
+	expected.SwVer = "010"
+	expected.Resp = true
+	msg = parseOne(t, "ST300NPT;Res;100850000;010; 02;0;0;0;0;0;500;300;5;10;70;5;10;70;0\r")
+	assert.Equal(t, NPTCmd, msg.Type)
+	assert.Equal(t, expected, msg.NPT)
+}
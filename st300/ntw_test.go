@@ -0,0 +1,41 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestST300NTW(t *testing.T) {
+	msg := parseOne(t, "ST300NTW;100850000;02;0;internet;;;111.111.111.111;8600;;;;\r")
+	assert.Equal(t, NTWCmd, msg.Type)
+	assert.Equal(t, &ST300NTW{
+		DevID: "100850000",
+		SwVer: "02",
+		NTWParams: st.NTWParams{
+			Auth:       "0",
+			APN:        "internet",
+			ServerIP:   "111.111.111.111",
+			ServerPort: 8600,
+		},
+	}, msg.NTW)
+}
+
+func TestST300NTWRes(t *testing.T) {
+	msg := parseOne(t, "ST300NTW;Res;100850000;010;A1;tim.br;tim;tim;111.111.111.111;8600;;;;\r")
+	assert.Equal(t, NTWCmd, msg.Type)
+	assert.Equal(t, &ST300NTW{
+		DevID: "100850000",
+		SwVer: "010",
+		NTWParams: st.NTWParams{
+			Auth:       "A1",
+			APN:        "tim.br",
+			UserID:     "tim",
+			UserPwd:    "tim",
+			ServerIP:   "111.111.111.111",
+			ServerPort: 8600,
+		},
+		Resp: true,
+	}, msg.NTW)
+}
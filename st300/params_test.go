@@ -0,0 +1,40 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseOne parses a single frame, checking it has no errors.
+func parseOne(t *testing.T, frame string) *Msg {
+	p := ParseString(frame, ParserOpts{})
+	require.True(t, p.Next())
+	require.Nil(t, p.Error())
+	msg := p.Msg()
+	require.NotNil(t, msg)
+	require.Nil(t, msg.ParsingError)
+	assert.Equal(t, frame, string(msg.Frame))
+	assert.False(t, p.Next())
+	return msg
+}
+
+func TestParamsCount(t *testing.T) {
+	p := ParseString("ST300RPT;100850000;02;180;120;60\r", ParserOpts{})
+	require.True(t, p.Next())
+	msg := p.Msg()
+	assert.Equal(t, RPTCmd, msg.Type)
+	assert.NotNil(t, msg.ParsingError)
+	assert.Equal(t, "ST300RPT;100850000;02;180;120;60\r", string(msg.Frame))
+	assert.False(t, p.Next())
+}
+
+func TestInvalidParam(t *testing.T) {
+	p := ParseString("ST300MSR;100852588;02;600;0.04;A;0.7\r", ParserOpts{})
+	require.True(t, p.Next())
+	msg := p.Msg()
+	assert.Equal(t, MSRCmd, msg.Type)
+	assert.EqualError(t, msg.ParsingError, `invalid MSR parameter Deceleration: "A"`)
+	assert.False(t, p.Next())
+}
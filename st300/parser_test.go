@@ -0,0 +1,54 @@
+package st300
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMixedStream(t *testing.T) {
+	stt := []byte("ST300STT;205150043;02;529;20150716;19:33:30;6d6113;-32.644923;-071.424437;000.039;000.00;10;1;724692;12.89;000000;1;5069;001257;4.2;0\r")
+	malformed := []byte("ST300ALT;100850000;01;010;20081017;07:41:56;00100;+37.478519;X+126.886819;000.012;000.00;9;1;0;15.30;001100;1;0;4.5;1\r")
+	junk := []byte{0x00, 'S', 'x', 0x02, 0xFF, 0xFF, '\r', '\n'}
+
+	var buf bytes.Buffer
+	buf.Write(stt)
+	buf.Write(sttZipSpec)
+	buf.Write(junk)
+	buf.Write(stt)
+	buf.Write(malformed)
+	buf.Write(sttZipSpec)
+
+	var msgs []*Msg
+	p := ParseBytes(buf.Bytes(), ParserOpts{})
+	for p.Next() {
+		msgs = append(msgs, p.Msg())
+	}
+	require.Nil(t, p.Error())
+	require.Len(t, msgs, 7)
+
+	assert.Nil(t, msgs[0].ParsingError)
+	assert.Equal(t, stt, msgs[0].Frame)
+
+	assert.Nil(t, msgs[1].ParsingError)
+	assert.Equal(t, sttZipSpec, msgs[1].Frame)
+
+	assert.Equal(t, st.ErrSkippedBytes, msgs[2].ParsingError)
+	assert.Equal(t, junk, msgs[2].Frame)
+
+	assert.Nil(t, msgs[3].ParsingError)
+	assert.Equal(t, stt, msgs[3].Frame)
+
+	// the malformed frame is returned up to the invalid field, the rest of it is skipped
+	assert.Equal(t, ALTReport, msgs[4].Type)
+	assert.NotNil(t, msgs[4].ParsingError)
+	assert.Equal(t, st.ErrSkippedBytes, msgs[5].ParsingError)
+	assert.Equal(t, malformed, append(msgs[4].Frame, msgs[5].Frame...))
+
+	assert.Nil(t, msgs[6].ParsingError)
+	assert.Equal(t, STTReport, msgs[6].Type)
+	assert.Equal(t, sttZipSpec, msgs[6].Frame)
+}
@@ -2,9 +2,11 @@ package st300
 
 import (
 	"testing"
+	"time"
 
 	"github.com/larixsource/suntech/st"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // page 54, rev 1.37
@@ -34,8 +36,58 @@ var sttZipSpec = []byte{0x02,
 	0x01,
 	0x03}
 
-func TestParseErrZipUnsupported(t *testing.T) {
+func TestParseSTTZip(t *testing.T) {
 	p := ParseBytes(sttZipSpec, ParserOpts{})
+	assert.True(t, p.Next())
+	assert.Nil(t, p.Error())
+	msg := p.Msg()
+	require.NotNil(t, msg)
+
+	expectedSTT := &StatusReport{
+		Hdr:              STTReport,
+		DevID:            "100850001",
+		Model:            st.ST300,
+		SwVer:            10,
+		Timestamp:        time.Date(2008, 10, 17, 7, 41, 56, 0, time.UTC),
+		Cell:             "2F100",
+		Latitude:         37.478519,
+		Longitude:        126.886819,
+		Speed:            32.51,
+		Course:           0,
+		Satellites:       9,
+		GPSFixed:         true,
+		Distance:         500,
+		PowerVolt:        15.3,
+		IO:               "001100",
+		Mode:             st.ParkingMode,
+		MsgNum:           72,
+		DrivingHourMeter: 2000,
+		BackupVolt:       4.5,
+		RealTime:         true,
+	}
+
+	assert.EqualValues(t, st.ST300, msg.Model)
+	assert.Equal(t, sttZipSpec, msg.Frame)
+	assert.Nil(t, msg.ParsingError)
+
+	assert.Equal(t, STTReport, msg.Type)
+	equalSTT(t, expectedSTT, msg.STT)
+
+	assert.False(t, p.Next())
+	assert.Nil(t, p.Error())
+}
+
+func TestParseZipInvalidETX(t *testing.T) {
+	frame := make([]byte, len(sttZipSpec))
+	copy(frame, sttZipSpec)
+	frame[len(frame)-1] = 0x04
+
+	p := ParseBytes(frame, ParserOpts{})
+	assert.True(t, p.Next())
+	msg := p.Msg()
+	require.NotNil(t, msg)
+	// without the ETX, it doesn't look like a zip frame
+	assert.Equal(t, st.ErrSkippedBytes, msg.ParsingError)
+	assert.Equal(t, frame, msg.Frame)
 	assert.False(t, p.Next())
-	assert.Equal(t, p.Error(), st.ErrZipUnsupported)
 }
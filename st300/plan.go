@@ -0,0 +1,11 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/st"
+)
+
+// ConfigCommands returns the frames (without the CR) of the configuration commands that change the current
+// configuration of a device (like the one returned by Preset) into the desired one. See st.PlanConfig.
+func ConfigCommands(devID string, current, desired *st.DeviceConfig) ([][]byte, error) {
+	return st.PlanCommands("ST300", devID, current, desired)
+}
@@ -0,0 +1,25 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigCommands(t *testing.T) {
+	current := &st.DeviceConfig{
+		RPT: &st.RPTParams{ParkingInterval: 180, DrivingInterval: 120, EmergencyInterval: 60, EmergencyCount: 3},
+		MSR: &st.MSRParams{Interval: 10, Acceleration: 0.1, Deceleration: 0.1, Impact: 0.7},
+	}
+	desired := &st.DeviceConfig{
+		RPT: &st.RPTParams{ParkingInterval: 180, DrivingInterval: 120, EmergencyInterval: 60, EmergencyCount: 3},
+		MSR: &st.MSRParams{Interval: 600, Acceleration: 0.04, Deceleration: 0.04, Impact: 0.7},
+		DEV: &st.DEVParams{Params: []int{1}},
+	}
+	frames, err := ConfigCommands("100850000", current, desired)
+	require.Nil(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, "ST300MSR;100850000;02;600;0.04;0.04;0.7", string(frames[0]))
+}
@@ -0,0 +1,30 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestST300RPT(t *testing.T) {
+	expected := &ST300RPT{
+		DevID: "100850000",
+		SwVer: "02",
+		RPTParams: st.RPTParams{
+			ParkingInterval:   180,
+			DrivingInterval:   120,
+			EmergencyInterval: 60,
+			EmergencyCount:    3,
+		},
+	}
+	msg := parseOne(t, "ST300RPT;100850000;02;180;120;60;3;0;0;0;0;0\r")
+	assert.Equal(t, RPTCmd, msg.Type)
+	assert.Equal(t, expected, msg.RPT)
+
+	expected.SwVer = "010"
+	expected.Resp = true
+	msg = parseOne(t, "ST300RPT;Res;100850000;010;180;120;60;3;0;0;0;0;0\r")
+	assert.Equal(t, RPTCmd, msg.Type)
+	assert.Equal(t, expected, msg.RPT)
+}
@@ -0,0 +1,37 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST300SVC holds the service parameters of a device (SVC command).
+type ST300SVC struct {
+	DevID string
+	SwVer string
+	st.SVCParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (svc *ST300SVC) Command() ([]byte, error) {
+	return st.Command("ST300SVC", svc.DevID, svc.SwVer, &svc.SVCParams)
+}
+
+func parseSVC(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = SVCCmd
+
+	svc := &ST300SVC{}
+	msg.SVC = svc
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	svc.Resp, svc.DevID, svc.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &svc.SVCParams)
+}
@@ -0,0 +1,24 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestST300SVC(t *testing.T) {
+	msg := parseOne(t, "ST300SVC;100850000;02;1;120;0;0;0;0;1;1;1;0;0;0;0\r")
+	assert.Equal(t, SVCCmd, msg.Type)
+	assert.Equal(t, &ST300SVC{
+		DevID: "100850000",
+		SwVer: "02",
+		SVCParams: st.SVCParams{
+			Parking:           1,
+			ParkingTime:       120,
+			AngleReport:       1,
+			StaticFilter:      1,
+			IgnitionOffReport: 1,
+		},
+	}, msg.SVC)
+}
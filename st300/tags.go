@@ -0,0 +1,95 @@
+package st300
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/larixsource/suntech/st"
+)
+
+var (
+	ErrTagPages      = errors.New("invalid pages of driver tags")
+	ErrTagIncomplete = errors.New("incomplete driver tags")
+)
+
+// maxPageTags is the maximum count of driver tags in a frame. A HGD response with more tags is split in frames of
+// maxPageTags tags.
+const maxPageTags = 20
+
+// tagDataLen is the length of the data of a driver tag (hex digits).
+const tagDataLen = 14
+
+// DriverTag is a driver ID tag saved in the device, at an index (1 to 999).
+type DriverTag struct {
+	Index int
+	Data  string
+}
+
+// checkTags validates the tags of a frame.
+func checkTags(cmd string, tags []DriverTag) error {
+	if len(tags) == 0 || len(tags) > maxPageTags {
+		return &st.ParamError{Cmd: cmd, Param: "Tags", Value: len(tags)}
+	}
+	for _, tag := range tags {
+		if err := checkTagIndex(cmd, tag.Index); err != nil {
+			return err
+		}
+		if len(tag.Data) != tagDataLen || !isHex(tag.Data) {
+			return &st.ParamError{Cmd: cmd, Param: "Data", Value: tag.Data}
+		}
+	}
+	return nil
+}
+
+// writeTags writes the index and the data of each tag.
+func writeTags(w *st.AsciiWriter, tags []DriverTag) {
+	for _, tag := range tags {
+		w.Field(fmt.Sprintf("%03d", tag.Index))
+		w.Field(tag.Data)
+	}
+}
+
+// decodeTags decodes pairs of index and data. A trailing empty field (a frame ending with ';') is ignored.
+func decodeTags(cmd string, fields []string) ([]DriverTag, error) {
+	if len(fields)%2 == 1 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) == 0 || len(fields)%2 == 1 || len(fields) > 2*maxPageTags {
+		return nil, fmt.Errorf("invalid %s parameters count: %d", cmd, len(fields))
+	}
+	tags := make([]DriverTag, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		index, err := decodeTagIndex(cmd, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		// some responses have shorter data (like "00003333")
+		data := fields[i+1]
+		if data == "" || len(data) > tagDataLen || !isHex(data) {
+			return nil, &st.ParamError{Cmd: cmd, Param: "Data", Value: data}
+		}
+		tags = append(tags, DriverTag{Index: index, Data: data})
+	}
+	return tags, nil
+}
+
+func checkTagIndex(cmd string, index int) error {
+	if index < 1 || index > 999 {
+		return &st.ParamError{Cmd: cmd, Param: "Index", Value: index}
+	}
+	return nil
+}
+
+func decodeTagIndex(cmd string, s string) (int, error) {
+	index, err := strconv.Atoi(s)
+	if err != nil || len(s) != 3 || checkTagIndex(cmd, index) != nil {
+		return 0, &st.ParamError{Cmd: cmd, Param: "Index", Value: s}
+	}
+	return index, nil
+}
+
+func isHex(s string) bool {
+	return strings.Trim(s, "0123456789abcdefABCDEF") == ""
+}
@@ -0,0 +1,139 @@
+package st300
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTP(t *testing.T) {
+	frame := "ST300HTP;100850000;02;1;1;16344;16;3200;1500;4000;0;0100004E160000;;1;10;1000;0;0"
+	msg := parseOne(t, frame+"\r")
+	assert.Equal(t, HTPCmd, msg.Type)
+	require.NotNil(t, msg.HTP)
+	assert.Equal(t, "0100004E160000", msg.HTP.MasterTag)
+
+	b, err := msg.HTP.Command()
+	require.Nil(t, err)
+	assert.Equal(t, frame, string(b))
+
+	msg.HTP.Password = "1;2"
+	_, err = msg.HTP.Command()
+	assert.IsType(t, &st.ParamError{}, err)
+}
+
+func TestHAD(t *testing.T) {
+	had := ST300HAD{
+		DevID: "100850000",
+		Tags: []DriverTag{
+			{Index: 1, Data: "00001000000000"},
+			{Index: 2, Data: "00002000ABCDEF"},
+			{Index: 5, Data: "00003333AAAAAA"},
+		},
+	}
+	b, err := had.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST300HAD;100850000;02;001;00001000000000;002;00002000ABCDEF;005;00003333AAAAAA;", string(b))
+
+	msg := parseOne(t, string(b)+"\r")
+	had.SwVer = st.CmdVersion
+	assert.Equal(t, &had, msg.HAD)
+
+	msg = parseOne(t, "ST300HAD;Res;100850000;267;001;00001000000000;002;00002000ABCDEF;005;00003333\r")
+	assert.True(t, msg.HAD.Resp)
+	require.Len(t, msg.HAD.Tags, 3)
+	assert.Equal(t, DriverTag{Index: 5, Data: "00003333"}, msg.HAD.Tags[2])
+}
+
+func TestHADErrors(t *testing.T) {
+	testCases := []struct {
+		tags  []DriverTag
+		param string
+	}{
+		{tags: nil, param: "Tags"},
+		{tags: make([]DriverTag, 21), param: "Tags"},
+		{tags: []DriverTag{{Index: 0, Data: "00001000000000"}}, param: "Index"},
+		{tags: []DriverTag{{Index: 1000, Data: "00001000000000"}}, param: "Index"},
+		{tags: []DriverTag{{Index: 1, Data: "0000100000000"}}, param: "Data"},
+		{tags: []DriverTag{{Index: 1, Data: "0000100000000X"}}, param: "Data"},
+	}
+	for _, tc := range testCases {
+		had := ST300HAD{DevID: "100850000", Tags: tc.tags}
+		_, err := had.Command()
+		require.IsType(t, &st.ParamError{}, err)
+		assert.Equal(t, tc.param, err.(*st.ParamError).Param)
+	}
+}
+
+func TestHRD(t *testing.T) {
+	hrd := ST300HRD{DevID: "100850000", Index: 1}
+	b, err := hrd.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST300HRD;100850000;02;001", string(b))
+
+	msg := parseOne(t, "ST300HRD;Res;100850000;267;001\r")
+	assert.Equal(t, &ST300HRD{DevID: "100850000", SwVer: "267", Index: 1, Resp: true}, msg.HRD)
+}
+
+func TestHGD(t *testing.T) {
+	hgd := ST300HGD{DevID: "100850000", Index: 1}
+	b, err := hgd.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST300HGD;100850000;02;001", string(b))
+
+	msg := parseOne(t, "ST300HGD;Res;100850000;267;001;00001000000000\r")
+	assert.Equal(t, []DriverTag{{Index: 1, Data: "00001000000000"}}, msg.HGD.Tags)
+	assert.False(t, msg.HGD.More())
+}
+
+func TestAssembleTags(t *testing.T) {
+	frames := "ST300HGD;Res;100850000;267;001;01000000000101;002;01000000000102;003;01000000000103;004;01000000000104;005;01000000000105;006;01000000000106;007;01000000000107;008;01000000000108;009;01000000000101;010;01000000000101;011;01000000000111;012;01000000000112;013;01000000000113;014;01000000000114;015;01000000000115;016;01000000000116;017;01000000000117;018;01000000000118;019;01000000000119;020;01000000000120;\r" +
+		"ST300HGD;Res;100850000;267;021;01000000000121\r"
+	var pages []*ST300HGD
+	p := ParseString(frames, ParserOpts{})
+	for p.Next() {
+		require.Nil(t, p.Msg().ParsingError)
+		pages = append(pages, p.Msg().HGD)
+	}
+	require.Len(t, pages, 2)
+	assert.True(t, pages[0].More())
+
+	tags, err := AssembleTags(pages)
+	require.Nil(t, err)
+	require.Len(t, tags, 21)
+	for i, tag := range tags {
+		assert.Equal(t, i+1, tag.Index)
+	}
+	assert.Equal(t, "01000000000120", tags[19].Data)
+	assert.Equal(t, "01000000000121", tags[20].Data)
+
+	_, err = AssembleTags(pages[:1])
+	assert.Equal(t, ErrTagIncomplete, err)
+	_, err = AssembleTags([]*ST300HGD{pages[1], pages[0]})
+	assert.Equal(t, ErrTagPages, err)
+	_, err = AssembleTags(nil)
+	assert.Equal(t, ErrTagIncomplete, err)
+}
+
+func TestTagErrors(t *testing.T) {
+	frames := []string{
+		"ST300HGD;Res;100850000;267;001\r",
+		"ST300HGD;Res;100850000;267;1;00001000000000\r",
+		"ST300HGD;Res;100850000;267;001;0000100000000G\r",
+		"ST300HRD;Res;100850000;267;001;002\r",
+		"ST300HTP;Res;100850000;267;1;1\r",
+		"ST300HAD;100850000;02;001;00001000000000;" +
+			"002;00001000000000;003;00001000000000;004;00001000000000;005;00001000000000;006;00001000000000;" +
+			"007;00001000000000;008;00001000000000;009;00001000000000;010;00001000000000;011;00001000000000;" +
+			"012;00001000000000;013;00001000000000;014;00001000000000;015;00001000000000;016;00001000000000;" +
+			"017;00001000000000;018;00001000000000;019;00001000000000;020;00001000000000;021;00001000000000\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		assert.NotNil(t, p.Msg().ParsingError, frame)
+		assert.Equal(t, frame, string(p.Msg().Frame))
+	}
+}
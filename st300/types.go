@@ -36,6 +36,14 @@ const (
 	DEXReport
 
 	CMD
+	DPACmd
+	HTPCmd
+	HADCmd
+	HRDCmd
+	HGDCmd
+	ECUCmd
+	GESCmd
+	GEDCmd
 )
 
 type Msg struct {
@@ -44,7 +52,26 @@ type Msg struct {
 
 	Type MsgType
 
-	CGF *ST300CGF
+	CGF    *ST300CGF
+	NTW    *ST300NTW
+	RPT    *ST300RPT
+	EVTCmd *ST300EVT
+	GSM    *ST300GSM
+	SVC    *ST300SVC
+	MBV    *ST300MBV
+	MSR    *ST300MSR
+	ADP    *ST300ADP
+	NPT    *ST300NPT
+	CMD    *ST300CMD
+	HTP    *ST300HTP
+	HAD    *ST300HAD
+	HRD    *ST300HRD
+	HGD    *ST300HGD
+	ECU    *ST300ECU
+	GES    *ST300GES
+	GED    *ST300GED
+	DPA    *ST300DPA
+	LTM    *ST300LTM
 
 	STT *StatusReport
 	EMG *EmergencyReport
@@ -55,3 +82,20 @@ type Msg struct {
 
 	ParsingError error
 }
+
+// Report returns the report of the message (STT, EMG, EVT or ALT), regardless of its Type, or nil when the message
+// isn't a report.
+func (m *Msg) Report() st.Report {
+	switch {
+	case m.STT != nil:
+		return m.STT
+	case m.EMG != nil:
+		return m.EMG
+	case m.EVT != nil:
+		return m.EVT
+	case m.ALT != nil:
+		return m.ALT
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,177 @@
+package st300
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// zipIOBits is the number of IO bits reported by a ST300 (the same count of chars of the ascii IO field).
+const zipIOBits = 6
+
+func parseSTTZip(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = STTReport
+
+	stt := &StatusReport{}
+	msg.STT = stt
+	stt.Hdr = STTReport
+
+	devID, err := st.ZipDevID(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.DevID = devID
+
+	model, err := st.ZipModel(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	msg.Model = model
+	stt.Model = model
+	if !knownModel(model) {
+		msg.ParsingError = st.ErrUnsupportedModel
+		return
+	}
+
+	swVer, err := st.ZipSwVer(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.SwVer = swVer
+
+	ts, err := st.ZipTimestamp(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.Timestamp = ts
+
+	cell, err := st.ZipCell(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.Cell = cell
+
+	lat, err := st.ZipLat(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.Latitude = lat
+
+	lon, err := st.ZipLon(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.Longitude = lon
+
+	speed, err := st.ZipSpeed(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.Speed = speed
+
+	course, err := st.ZipCourse(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.Course = course
+
+	satellites, fix, err := st.ZipSatFix(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.Satellites = satellites
+	stt.GPSFixed = fix
+
+	distance, err := st.ZipDistance(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.Distance = distance
+
+	powerVolt, err := st.ZipPowerVolt(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.PowerVolt = powerVolt
+
+	ioStatus, err := st.ZipIO(lex, zipIOBits)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.IO = ioStatus
+
+	mode, err := st.ZipMode(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.Mode = mode
+
+	msgNum, err := st.ZipMsgNum(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.MsgNum = msgNum
+
+	hmeter, err := st.ZipDrivingHourMeter(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.DrivingHourMeter = hmeter
+
+	backupVolt, err := st.ZipBackupVolt(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.BackupVolt = backupVolt
+
+	// the fields after RealTime depend on the model, and they are kept only in the raw frame
+	realTime, err := st.ZipBit(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.RealTime = realTime
+
+	return
+}
+
+// MarshalZip returns the zip frame of the report. Fields that can't be represented in a zip frame are reported as
+// an error.
+func (stt *StatusReport) MarshalZip() ([]byte, error) {
+	var w st.ZipWriter
+	w.DevID(stt.DevID)
+	w.Model(stt.Model)
+	w.SwVer(stt.SwVer)
+	w.Timestamp(stt.Timestamp)
+	w.Cell(stt.Cell)
+	w.Lat(stt.Latitude)
+	w.Lon(stt.Longitude)
+	w.Speed(stt.Speed)
+	w.Course(stt.Course)
+	w.SatFix(stt.Satellites, stt.GPSFixed)
+	w.Distance(stt.Distance)
+	w.PowerVolt(stt.PowerVolt)
+	w.IO(stt.IO)
+	w.Mode(stt.Mode)
+	w.MsgNum(stt.MsgNum)
+	w.DrivingHourMeter(stt.DrivingHourMeter)
+	w.BackupVolt(stt.BackupVolt)
+	w.Bit(stt.RealTime)
+	return w.Frame(st.ZipSTT)
+}
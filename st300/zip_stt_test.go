@@ -0,0 +1,121 @@
+package st300
+
+import (
+	"testing"
+	"time"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSTTMarshalZip(t *testing.T) {
+	stt := &StatusReport{
+		Hdr:              STTReport,
+		DevID:            "100850001",
+		Model:            st.ST300,
+		SwVer:            10,
+		Timestamp:        time.Date(2008, 10, 17, 7, 41, 56, 0, time.UTC),
+		Cell:             "2F100",
+		Latitude:         37.478519,
+		Longitude:        126.886819,
+		Speed:            32.51,
+		Course:           0,
+		Satellites:       9,
+		GPSFixed:         true,
+		Distance:         500,
+		PowerVolt:        15.3,
+		IO:               "001100",
+		Mode:             st.ParkingMode,
+		MsgNum:           72,
+		DrivingHourMeter: 2000,
+		BackupVolt:       4.5,
+		RealTime:         true,
+	}
+	frame, err := stt.MarshalZip()
+	require.Nil(t, err)
+
+	// the spec frame has a tail (after RealTime) that isn't part of the report, and the longitude is rounded
+	// from its float32 value (126.886818)
+	expected := []byte{st.STX, 0x00, 0x32}
+	expected = append(expected, sttZipSpec[3:53]...)
+	expected = append(expected, st.ETX)
+	expected[28] = 0x18
+	assert.Equal(t, expected, frame)
+
+	// round trip
+	p := ParseBytes(frame, ParserOpts{})
+	require.True(t, p.Next())
+	msg := p.Msg()
+	require.Nil(t, msg.ParsingError)
+	equalSTT(t, stt, msg.STT)
+
+	again, err := msg.STT.MarshalZip()
+	require.Nil(t, err)
+	assert.Equal(t, frame, again)
+}
+
+func TestSTTMarshalZipNegativeCoords(t *testing.T) {
+	stt := &StatusReport{
+		DevID:     "205150043",
+		Model:     st.ST340,
+		SwVer:     529,
+		Timestamp: time.Date(2015, 7, 16, 19, 33, 30, 0, time.UTC),
+		Cell:      "6D6113",
+		Latitude:  -32.644923,
+		Longitude: -71.424437,
+		Speed:     0.03,
+		IO:        "000000",
+		Mode:      st.DrivingMode,
+	}
+	frame, err := stt.MarshalZip()
+	require.Nil(t, err)
+
+	p := ParseBytes(frame, ParserOpts{})
+	require.True(t, p.Next())
+	msg := p.Msg()
+	require.Nil(t, msg.ParsingError)
+	assert.Equal(t, frame, msg.Frame)
+	assert.Equal(t, stt.Cell, msg.STT.Cell)
+	assert.InEpsilon(t, stt.Latitude, msg.STT.Latitude, epsilon)
+	assert.InEpsilon(t, stt.Longitude, msg.STT.Longitude, epsilon)
+	assert.Equal(t, stt.Speed, msg.STT.Speed)
+}
+
+func TestSTTMarshalZipErrors(t *testing.T) {
+	valid := StatusReport{
+		DevID:     "100850000",
+		Model:     st.ST300,
+		Timestamp: time.Date(2008, 10, 17, 7, 41, 56, 0, time.UTC),
+		Cell:      "00100",
+		IO:        "001100",
+		Mode:      st.ParkingMode,
+	}
+	_, err := valid.MarshalZip()
+	require.Nil(t, err)
+
+	stt := valid
+	stt.DevID = "10085"
+	_, err = stt.MarshalZip()
+	assert.Equal(t, st.ErrInvalidDevID, err)
+
+	stt = valid
+	stt.Longitude = -170.5
+	_, err = stt.MarshalZip()
+	assert.Equal(t, st.ErrInvalidLng, err)
+
+	stt = valid
+	stt.Latitude = -0.5
+	_, err = stt.MarshalZip()
+	assert.Equal(t, st.ErrInvalidLat, err)
+
+	stt = valid
+	stt.IO = "00x100"
+	_, err = stt.MarshalZip()
+	assert.Equal(t, st.ErrInvalidIO, err)
+
+	stt = valid
+	stt.Mode = 0
+	_, err = stt.MarshalZip()
+	assert.Equal(t, st.ErrInvalidMode, err)
+}
@@ -12,6 +12,15 @@ type AlertReport struct {
 	BackupVolt       float32
 	RealTime         bool
 	ADC              float32
+
+	// Fence and GroupID are the geofence group of the alert (like entering or leaving it), if there's one
+	Fence   st.FenceType
+	GroupID int
+}
+
+// Common returns the fields of the report shared with the other models.
+func (alt *AlertReport) Common() st.CommonReport {
+	return alt.common(alt.BackupVolt)
 }
 
 func parseALTAscii(lex *lexer.Lexer, msg *Msg) {
@@ -26,13 +35,15 @@ func parseALTAscii(lex *lexer.Lexer, msg *Msg) {
 		return
 	}
 
-	altID, token, err := st.AsciiAltID(lex)
+	altID, fence, groupID, token, err := st.AsciiGeoAltID(lex)
 	msg.Frame = append(msg.Frame, token.Literal...)
 	if err != nil {
 		msg.ParsingError = err
 		return
 	}
 	alt.AltID = altID
+	alt.Fence = fence
+	alt.GroupID = groupID
 
 	hmeter, token, err := st.AsciiDrivingHourMeter(lex)
 	msg.Frame = append(msg.Frame, token.Literal...)
@@ -68,3 +79,17 @@ func parseALTAscii(lex *lexer.Lexer, msg *Msg) {
 
 	return
 }
+
+// MarshalASCII returns the ascii frame of the report.
+func (alt *AlertReport) MarshalASCII() ([]byte, error) {
+	var w st.AsciiWriter
+	if err := writeAsciiCommon(&w, "ST600ALT", &alt.CommonReport); err != nil {
+		return nil, err
+	}
+	w.GeoAltID(alt.AltID, alt.Fence, alt.GroupID)
+	w.DrivingHourMeter(alt.DrivingHourMeter)
+	w.BackupVolt(alt.BackupVolt)
+	w.Bit(alt.RealTime)
+	w.ADC(alt.ADC)
+	return w.Frame()
+}
@@ -16,6 +16,8 @@ func equalALT(t *testing.T, expected *AlertReport, actual *AlertReport) {
 	require.NotNil(t, actual)
 	equalCommon(t, &expected.CommonReport, &actual.CommonReport)
 	assert.Equal(t, expected.AltID, actual.AltID)
+	assert.Equal(t, expected.Fence, actual.Fence)
+	assert.Equal(t, expected.GroupID, actual.GroupID)
 	assert.Equal(t, expected.DrivingHourMeter, actual.DrivingHourMeter)
 	assert.Equal(t, expected.BackupVolt, actual.BackupVolt)
 	assert.Equal(t, expected.RealTime, actual.RealTime)
@@ -104,3 +106,66 @@ func TestALT600(t *testing.T) {
 	}
 	assert.False(t, p.Next())
 }
+
+func TestALTGeofence(t *testing.T) {
+	frameTemplate := "ST600ALT;205951725;20;325;20151223;13:32:30;001cbf75;730;2;4e39;33;-33.363867;-070.670218;000.122;000.00;5;1;190269102;12.89;000000;%s;183230;4.5;0;0.00\r"
+	testCases := []struct {
+		altID   string
+		alt     st.AlertType
+		fence   st.FenceType
+		groupID int
+	}{
+		{"6002", st.EnteredGeoFenceAlt, st.CircleFence, 2},
+		{"5001", st.ExitedGeoFenceAlt, st.CircleFence, 1},
+		{"6P02", st.EnteredGeoFenceAlt, st.PolygonFence, 2},
+		{"5P01", st.ExitedGeoFenceAlt, st.PolygonFence, 1},
+		{"1P02", st.StartOverSpeedAlt, st.PolygonFence, 2},
+		{"2P01", st.StopOverSpeedAlt, st.PolygonFence, 1},
+		{"33", st.IgnitionOnAlt, st.NoFence, 0},
+	}
+	for _, tc := range testCases {
+		frame := fmt.Sprintf(frameTemplate, tc.altID)
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		msg := p.Msg()
+		require.Nil(t, msg.ParsingError, tc.altID)
+		require.NotNil(t, msg.ALT)
+		assert.Equal(t, tc.alt, msg.ALT.AltID)
+		assert.Equal(t, tc.fence, msg.ALT.Fence)
+		assert.Equal(t, tc.groupID, msg.ALT.GroupID)
+		assert.EqualValues(t, 183230, msg.ALT.DrivingHourMeter)
+		assert.False(t, p.Next())
+
+		// and back to the same frame
+		b, err := msg.ALT.MarshalASCII()
+		require.Nil(t, err)
+		alt, err := parseALT(b)
+		require.Nil(t, err)
+		assert.Equal(t, msg.ALT.Fence, alt.Fence)
+		assert.Equal(t, msg.ALT.GroupID, alt.GroupID)
+		assert.Contains(t, string(b), ";"+tc.altID+";")
+	}
+}
+
+func TestALTGeofenceErrors(t *testing.T) {
+	frameTemplate := "ST600ALT;205951725;20;325;20151223;13:32:30;001cbf75;730;2;4e39;33;-33.363867;-070.670218;000.122;000.00;5;1;190269102;12.89;000000;%s;183230;4.5;0;0.00\r"
+	for _, altID := range []string{"6P", "P02", "6PA2", "7002", "60022"} {
+		p := ParseString(fmt.Sprintf(frameTemplate, altID), ParserOpts{})
+		require.True(t, p.Next())
+		assert.NotNil(t, p.Msg().ParsingError, altID)
+	}
+
+	alt := testALT
+	alt.AltID = st.IgnitionOnAlt
+	alt.Fence = st.CircleFence
+	_, err := alt.MarshalASCII()
+	assert.Equal(t, st.ErrInvalidAltID, err)
+}
+
+func parseALT(frame []byte) (*AlertReport, error) {
+	p := ParseBytes(frame, ParserOpts{})
+	if !p.Next() {
+		return nil, p.Error()
+	}
+	return p.Msg().ALT, p.Msg().ParsingError
+}
@@ -28,3 +28,11 @@ func parseALVAscii(lex *lexer.Lexer, msg *Msg) {
 
 	return
 }
+
+// MarshalASCII returns the ascii frame of the report.
+func (alv *AliveReport) MarshalASCII() ([]byte, error) {
+	var w st.AsciiWriter
+	w.Hdr("ST600ALV")
+	w.DevID(alv.DevID)
+	return w.Frame()
+}
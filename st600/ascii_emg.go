@@ -14,6 +14,11 @@ type EmergencyReport struct {
 	ADC              float32
 }
 
+// Common returns the fields of the report shared with the other models.
+func (emg *EmergencyReport) Common() st.CommonReport {
+	return emg.common(emg.BackupVolt)
+}
+
 func parseEMGAscii(lex *lexer.Lexer, msg *Msg) {
 	msg.Type = EMGReport
 
@@ -68,3 +73,17 @@ func parseEMGAscii(lex *lexer.Lexer, msg *Msg) {
 
 	return
 }
+
+// MarshalASCII returns the ascii frame of the report.
+func (emg *EmergencyReport) MarshalASCII() ([]byte, error) {
+	var w st.AsciiWriter
+	if err := writeAsciiCommon(&w, "ST600EMG", &emg.CommonReport); err != nil {
+		return nil, err
+	}
+	w.EmgID(emg.EmgID)
+	w.DrivingHourMeter(emg.DrivingHourMeter)
+	w.BackupVolt(emg.BackupVolt)
+	w.Bit(emg.RealTime)
+	w.ADC(emg.ADC)
+	return w.Frame()
+}
@@ -16,6 +16,11 @@ type EventReport struct {
 	ADC              float32
 }
 
+// Common returns the fields of the report shared with the other models.
+func (evt *EventReport) Common() st.CommonReport {
+	return evt.common(evt.BackupVolt)
+}
+
 func parseEVTAscii(lex *lexer.Lexer, msg *Msg) {
 	msg.Type = EVTReport
 
@@ -33,8 +38,7 @@ func parseEVTAscii(lex *lexer.Lexer, msg *Msg) {
 	case token.OnlyDigits() && len(token.WithoutSuffix()) == 9:
 		evt.DevID = string(token.WithoutSuffix())
 	case bytes.Equal(st.ResLiteral, token.WithoutSuffix()):
-		msg.Type = UnknownMsg
-		msg.ParsingError = ErrUnknownHdr
+		parseEVTResp(lex, msg)
 		return
 	default:
 		msg.ParsingError = st.ErrInvalidDevID
@@ -204,3 +208,17 @@ func parseEVTAscii(lex *lexer.Lexer, msg *Msg) {
 
 	return
 }
+
+// MarshalASCII returns the ascii frame of the report.
+func (evt *EventReport) MarshalASCII() ([]byte, error) {
+	var w st.AsciiWriter
+	if err := writeAsciiCommon(&w, "ST600EVT", &evt.CommonReport); err != nil {
+		return nil, err
+	}
+	w.EvtID(evt.EvtID)
+	w.DrivingHourMeter(evt.DrivingHourMeter)
+	w.BackupVolt(evt.BackupVolt)
+	w.Bit(evt.RealTime)
+	w.ADC(evt.ADC)
+	return w.Frame()
+}
@@ -0,0 +1,106 @@
+package st600
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func marshalASCII(t *testing.T, msg *Msg) ([]byte, error) {
+	switch msg.Type {
+	case STTReport:
+		return msg.STT.MarshalASCII()
+	case EMGReport:
+		return msg.EMG.MarshalASCII()
+	case EVTReport:
+		return msg.EVT.MarshalASCII()
+	case ALTReport:
+		return msg.ALT.MarshalASCII()
+	case ALVReport:
+		return msg.ALV.MarshalASCII()
+	case UEXReport:
+		return msg.UEX.MarshalASCII()
+	default:
+		t.Fatalf("unexpected msg type: %v", msg.Type)
+		return nil, nil
+	}
+}
+
+// the coordinates of these frames are exact in a float32, so the frames are regenerated without any change
+func TestMarshalASCII(t *testing.T) {
+	frames := []string{
+		"ST600STT;205150043;21;529;20150716;19:33:30;6d6113;-32.500000;-071.125000;000.039;000.00;10;1;724692;12.89;00110000;1;5069;001257;4.2;0;12.35\r",
+		"ST600STT;205951725;20;325;20151224;10:10:44;001cbf72;730;2;4e39;47;-32.500000;-071.125000;000.056;000.00;6;1;190269159;12.79;000000;1;0053;183231;0.0;0;0.00\r",
+		"ST600EMG;205951725;20;325;20151223;13:32:30;001cbf75;730;2;4e39;33;-32.500000;-071.125000;000.122;000.00;5;1;190269102;12.89;000000;1;183230;4.5;0;0.00\r",
+		"ST600EVT;205951725;20;325;20151223;13:32:30;001cbf75;730;2;4e39;33;-32.500000;-071.125000;000.122;000.00;5;1;190269102;12.89;000000;6;183230;4.5;0;0.00\r",
+		"ST600ALT;205951725;20;325;20151223;13:32:30;001cbf75;730;2;4e39;33;-32.500000;-071.125000;000.122;000.00;5;1;190269102;12.89;000000;33;183230;4.5;0;0.00\r",
+		"ST600ALV;600850777\r",
+		"ST600UEX;205951719;20;325;20160202;19:02:45;001cbf72;730;2;4e39;42;-32.500000;-071.125000;000.063;000.00;7;1;21;9.14;100000;47;$FMS8,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,@\r\n;99;000479;0.0;0\r",
+		"ST600UEX;205951719;20;325;20160202;19:02:45;001cbf72;730;2;4e39;42;-32.500000;-071.125000;000.063;000.00;7;1;21;9.14;100000;4;ABCD;0A;000479;0.0;0\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		msg := p.Msg()
+		require.Nil(t, msg.ParsingError, frame)
+
+		marshaled, err := marshalASCII(t, msg)
+		require.Nil(t, err, frame)
+		assert.Equal(t, frame, string(marshaled))
+	}
+}
+
+// coordinates with 6 decimals don't survive a float32, so the frames of devices are compared after parsing them again
+func TestMarshalASCIIRoundTrip(t *testing.T) {
+	frames := []string{
+		"ST600STT;205150043;21;529;20150716;19:33:30;6d6113;-32.644923;-071.424437;000.039;000.00;10;1;724692;12.89;00110000;1;5069;001257;4.2;0;12.35\r",
+		"ST600STT;205951725;20;325;20151224;10:10:44;001cbf72;730;2;4e39;47;-33.363627;-070.670525;000.056;000.00;6;1;190269159;12.79;000000;1;0053;183231;0.0;0;0.00\r",
+		"ST600EMG;205951725;20;325;20151223;13:32:30;001cbf75;730;2;4e39;33;-33.363867;-070.670218;000.122;000.00;5;1;190269102;12.89;000000;1;183230;4.5;0;0.00\r",
+		"ST600EVT;205951725;20;325;20151223;13:32:30;001cbf75;730;2;4e39;33;-33.363867;-070.670218;000.122;000.00;5;1;190269102;12.89;000000;6;183230;4.5;0;0.00\r",
+		"ST600ALT;205951725;20;325;20151223;13:32:30;001cbf75;730;2;4e39;33;-33.363867;-070.670218;000.122;000.00;5;1;190269102;12.89;000000;33;183230;4.5;0;0.00\r",
+		"ST600ALV;600850777\r",
+		"ST600UEX;205951719;20;325;20160202;19:02:45;001cbf72;730;2;4e39;42;-33.364049;-070.670220;000.063;000.00;7;1;21;9.14;100000;47;$FMS8,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,@\r\n;99;000479;0.0;0\r",
+		"ST600UEX;205951719;20;325;20160202;19:02:45;001cbf72;730;2;4e39;42;-33.364049;-070.670220;000.063;000.00;7;1;21;9.14;100000;4;ABCD;0A;000479;0.0;0\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		msg := p.Msg()
+		require.Nil(t, msg.ParsingError, frame)
+
+		marshaled, err := marshalASCII(t, msg)
+		require.Nil(t, err, frame)
+
+		p = ParseBytes(marshaled, ParserOpts{})
+		require.True(t, p.Next())
+		remsg := p.Msg()
+		require.Nil(t, remsg.ParsingError, string(marshaled))
+		assert.Equal(t, marshaled, remsg.Frame)
+
+		// same reports, the frames apart
+		expected, actual := *msg, *remsg
+		expected.Frame, actual.Frame = nil, nil
+		assert.Equal(t, expected, actual)
+
+		remarshaled, err := marshalASCII(t, remsg)
+		require.Nil(t, err)
+		assert.Equal(t, marshaled, remarshaled)
+	}
+}
+
+func TestMarshalASCIICellErrors(t *testing.T) {
+	frame := "ST600STT;205150043;21;529;20150716;19:33:30;6d6113;-32.644923;-071.424437;000.039;000.00;10;1;724692;12.89;00110000;1;5069;001257;4.2;0;12.35\r"
+	p := ParseString(frame, ParserOpts{})
+	require.True(t, p.Next())
+	stt := p.Msg().STT
+	require.NotNil(t, stt)
+
+	stt.Cell = Cell{Type: Cell2GType, Cell2G: "0001cbf72"}
+	_, err := stt.MarshalASCII()
+	assert.NotNil(t, err)
+
+	stt.Cell = Cell{Type: Cell3GType, Cell3G: Cell3G{CellID: "001cbf72", MCC: "730", MNC: "2", LAC: "4e39", SignalLevel: 1000}}
+	_, err = stt.MarshalASCII()
+	assert.Equal(t, ErrInvalidSignalLevel, err)
+}
@@ -15,6 +15,11 @@ type StatusReport struct {
 	ADC              float32
 }
 
+// Common returns the fields of the report shared with the other models.
+func (stt *StatusReport) Common() st.CommonReport {
+	return stt.common(stt.BackupVolt)
+}
+
 func parseSTTAscii(lex *lexer.Lexer, msg *Msg) {
 	msg.Type = STTReport
 
@@ -77,3 +82,18 @@ func parseSTTAscii(lex *lexer.Lexer, msg *Msg) {
 
 	return
 }
+
+// MarshalASCII returns the ascii frame of the report.
+func (stt *StatusReport) MarshalASCII() ([]byte, error) {
+	var w st.AsciiWriter
+	if err := writeAsciiCommon(&w, "ST600STT", &stt.CommonReport); err != nil {
+		return nil, err
+	}
+	w.Mode(stt.Mode)
+	w.MsgNum(stt.MsgNum)
+	w.DrivingHourMeter(stt.DrivingHourMeter)
+	w.BackupVolt(stt.BackupVolt)
+	w.Bit(stt.RealTime)
+	w.ADC(stt.ADC)
+	return w.Frame()
+}
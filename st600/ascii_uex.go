@@ -15,12 +15,13 @@ type ExtDataReport struct {
 	RealTime         bool
 }
 
+// Common returns the fields of the report shared with the other models.
+func (edr *ExtDataReport) Common() st.CommonReport {
+	return edr.common(edr.BackupVolt)
+}
+
 func (edr *ExtDataReport) Valid() bool {
-	var sum byte
-	for _, b := range edr.Data {
-		sum += b
-	}
-	return sum == edr.Checksum
+	return checksum(edr.Data) == edr.Checksum
 }
 
 func parseUEXAscii(lex *lexer.Lexer, msg *Msg) {
@@ -84,3 +85,18 @@ func parseUEXAscii(lex *lexer.Lexer, msg *Msg) {
 
 	return
 }
+
+// MarshalASCII returns the ascii frame of the report. Len is taken from the length of Data, and Checksum is written
+// as is (see Valid).
+func (uex *ExtDataReport) MarshalASCII() ([]byte, error) {
+	var w st.AsciiWriter
+	if err := writeAsciiCommon(&w, "ST600UEX", &uex.CommonReport); err != nil {
+		return nil, err
+	}
+	w.Data(uex.Data)
+	w.Checksum(uex.Checksum)
+	w.DrivingHourMeter(uex.DrivingHourMeter)
+	w.BackupVolt(uex.BackupVolt)
+	w.Bit(uex.RealTime)
+	return w.Frame()
+}
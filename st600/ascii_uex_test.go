@@ -128,3 +128,13 @@ func TestUEX600R2(t *testing.T) {
 
 	assert.False(t, p.Next())
 }
+
+func TestUEXInvalidChecksum(t *testing.T) {
+	frame := "ST600UEX;205951719;20;325;20160202;19:02:45;001cbf72;730;2;4e39;42;-33.364049;-070.670220;000.063;000.00;7;1;21;9.14;100000;47;$FMS8,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,@\r\n;9Z;000479;0.0;0\r"
+	p := ParseString(frame, ParserOpts{})
+	require.True(t, p.Next())
+	msg := p.Msg()
+	require.NotNil(t, msg)
+	assert.Equal(t, UEXReport, msg.Type)
+	assert.Equal(t, st.ErrInvalidChecksum, msg.ParsingError)
+}
@@ -2,6 +2,7 @@ package st600
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/larixsource/suntech/lexer"
@@ -152,3 +153,156 @@ func asciiSignalLevel(lex *lexer.Lexer) (signalLevel float32, token lexer.Token,
 	signalLevel = float32(sl64)
 	return
 }
+
+const (
+	// zipCommonLen is the length of the header and the common fields of a zip report with a 2G cell
+	zipCommonLen = 40
+
+	// zipCell3GExtraLen is the count of extra bytes used by a 3G cell in a zip report (11 bytes instead of 3)
+	zipCell3GExtraLen = 8
+)
+
+// zipCell3G tells if the body of a zip report (without STX, length and ETX) contains a 3G cell. Zip reports don't
+// carry a flag for the cell type, so it's inferred from the length of the body.
+func zipCell3G(body []byte) bool {
+	var tailLen int
+	switch body[0] {
+	case st.ZipSTT:
+		tailLen = 12
+	case st.ZipEMG, st.ZipEVT, st.ZipALT:
+		tailLen = 10
+	case st.ZipUEX:
+		// the tail length depends on the length of the data
+		if len(body) < zipCommonLen+2 {
+			return false
+		}
+		dataLen := int(body[zipCommonLen])<<8 | int(body[zipCommonLen+1])
+		if len(body) == zipCommonLen+2+dataLen+8 {
+			return false
+		}
+		if len(body) < zipCommonLen+zipCell3GExtraLen+2 {
+			return false
+		}
+		dataLen = int(body[zipCommonLen+zipCell3GExtraLen])<<8 | int(body[zipCommonLen+zipCell3GExtraLen+1])
+		tailLen = 2 + dataLen + 8
+	default:
+		return false
+	}
+	return len(body) == zipCommonLen+zipCell3GExtraLen+tailLen
+}
+
+// zipCell reads a 2G cell (3 bytes) or a 3G cell (CellID, MCC, MNC, LAC and SignalLevel, 11 bytes). The hex fields
+// are returned in uppercase, like the 2G cells.
+func zipCell(lex *lexer.Lexer, cell3G bool) (Cell, error) {
+	if !cell3G {
+		cell2G, err := st.ZipCell(lex)
+		if err != nil {
+			return Cell{}, err
+		}
+		return Cell{
+			Type:   Cell2GType,
+			Cell2G: cell2G,
+		}, nil
+	}
+
+	cell := Cell{
+		Type: Cell3GType,
+	}
+
+	cellID, err := st.ZipUint(lex, 4)
+	if err != nil {
+		return cell, st.ErrInvalidCell
+	}
+	cell.CellID = fmt.Sprintf("%08X", cellID)
+
+	mcc, err := st.ZipUint(lex, 2)
+	if err != nil {
+		return cell, ErrInvalidMCC
+	}
+	cell.MCC = strconv.FormatUint(mcc, 10)
+
+	mnc, err := st.ZipUint(lex, 2)
+	if err != nil {
+		return cell, ErrInvalidMNC
+	}
+	cell.MNC = strconv.FormatUint(mnc, 10)
+
+	lac, err := st.ZipUint(lex, 2)
+	if err != nil {
+		return cell, ErrInvalidLAC
+	}
+	cell.LAC = fmt.Sprintf("%X", lac)
+
+	sl, err := st.ZipUint(lex, 1)
+	if err != nil {
+		return cell, ErrInvalidSignalLevel
+	}
+	cell.SignalLevel = float32(sl)
+
+	return cell, nil
+}
+
+// writeZipCell writes a 2G or 3G cell, as read by zipCell.
+func writeZipCell(w *st.ZipWriter, cell Cell) error {
+	if cell.Type != Cell3GType {
+		w.Cell(cell.Cell2G)
+		return nil
+	}
+
+	cellID, err := strconv.ParseUint(cell.CellID, 16, 32)
+	if err != nil {
+		return st.ErrInvalidCell
+	}
+	w.Uint(cellID, 4)
+
+	mcc, err := strconv.ParseUint(cell.MCC, 10, 16)
+	if err != nil {
+		return ErrInvalidMCC
+	}
+	w.Uint(mcc, 2)
+
+	mnc, err := strconv.ParseUint(cell.MNC, 10, 16)
+	if err != nil {
+		return ErrInvalidMNC
+	}
+	w.Uint(mnc, 2)
+
+	lac, err := strconv.ParseUint(cell.LAC, 16, 16)
+	if err != nil {
+		return ErrInvalidLAC
+	}
+	w.Uint(lac, 2)
+
+	if cell.SignalLevel < 0 || cell.SignalLevel > 255 {
+		return ErrInvalidSignalLevel
+	}
+	w.Uint(uint64(cell.SignalLevel), 1)
+	return nil
+}
+
+// writeAsciiCell writes a 2G or 3G cell, as read by asciiCell3G.
+func writeAsciiCell(w *st.AsciiWriter, cell Cell) error {
+	if cell.Type != Cell3GType {
+		// a cell of 8 digits would be read as the CellID of a 3G cell
+		w.Hex(cell.Cell2G, 7, st.ErrInvalidCell)
+		return w.Err()
+	}
+
+	if len(cell.CellID) != 8 {
+		return st.ErrInvalidCell
+	}
+	w.Hex(cell.CellID, 8, st.ErrInvalidCell)
+	w.Hex(cell.MCC, 3, ErrInvalidMCC)
+	w.Hex(cell.MNC, 3, ErrInvalidMNC)
+	w.Hex(cell.LAC, 4, ErrInvalidLAC)
+	if err := w.Err(); err != nil {
+		return err
+	}
+
+	sl := strconv.FormatFloat(float64(cell.SignalLevel), 'f', -1, 32)
+	if cell.SignalLevel < 0 || len(sl) > 3 {
+		return ErrInvalidSignalLevel
+	}
+	w.Field(sl)
+	return w.Err()
+}
@@ -0,0 +1,91 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600CGF holds a circular geofence of a device (CGF command).
+type ST600CGF struct {
+	DevID string
+	SwVer string
+	st.CGFParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (cgf *ST600CGF) Command() ([]byte, error) {
+	return st.Command("ST600CGF", cgf.DevID, cgf.SwVer, &cgf.CGFParams)
+}
+
+func parseCGF(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = CGFCmd
+
+	cgf := &ST600CGF{}
+	msg.CGF = cgf
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	cgf.Resp, cgf.DevID, cgf.SwVer = resp, devID, swVer
+
+	geoID, token, err := st.AsciiGeoID(lex)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cgf.GeoID = geoID
+
+	active, token, err := st.AsciiBit(lex, false)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cgf.Active = active
+
+	lat, token, err := st.AsciiLat(lex)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cgf.Latitude = lat
+
+	lon, token, err := st.AsciiLon(lex)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cgf.Longitude = lon
+
+	radius, token, err := st.AsciiRadius(lex)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cgf.Radius = radius
+
+	in, token, err := st.AsciiBit(lex, false)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cgf.In = in
+
+	out, token, err := st.AsciiBit(lex, true)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cgf.Out = out
+}
@@ -0,0 +1,80 @@
+package st600
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestST600CGF(t *testing.T) {
+	cgf := ST600CGF{
+		DevID: "100850000",
+		CGFParams: st.CGFParams{
+			GeoID:     1,
+			Active:    true,
+			Latitude:  37.0,
+			Longitude: 127.0,
+			Radius:    50,
+			In:        true,
+			Out:       true,
+		},
+	}
+	cmd, err := cgf.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST600CGF;100850000;02;1;1;+37.000000;+127.000000;50;1;1", string(cmd))
+
+	p := ParseString(string(cmd)+"\r", ParserOpts{})
+	require.True(t, p.Next())
+	msg := p.Msg()
+	require.Nil(t, msg.ParsingError)
+	assert.Equal(t, CGFCmd, msg.Type)
+	cgf.SwVer = st.CmdVersion
+	assert.Equal(t, &cgf, msg.CGF)
+	assert.False(t, p.Next())
+}
+
+func TestST600CGFRes(t *testing.T) {
+	frame := "ST600CGF;Res;100850000;010;1;1;+37.000000;+127.000000;50;1;1\r"
+	p := ParseString(frame, ParserOpts{})
+	require.True(t, p.Next())
+	assert.Nil(t, p.Error())
+	msg := p.Msg()
+	require.NotNil(t, msg)
+	require.Nil(t, msg.ParsingError)
+	assert.Equal(t, frame, string(msg.Frame))
+
+	assert.Equal(t, CGFCmd, msg.Type)
+	assert.Equal(t, &ST600CGF{
+		DevID: "100850000",
+		SwVer: "010",
+		CGFParams: st.CGFParams{
+			GeoID:     1,
+			Active:    true,
+			Latitude:  37.0,
+			Longitude: 127.0,
+			Radius:    50,
+			In:        true,
+			Out:       true,
+		},
+		Resp: true,
+	}, msg.CGF)
+}
+
+func TestST600CGFErrors(t *testing.T) {
+	cgf := ST600CGF{
+		DevID: "100850000",
+		CGFParams: st.CGFParams{
+			GeoID:    1,
+			Latitude: 91,
+		},
+	}
+	_, err := cgf.Command()
+	assert.Equal(t, &st.ParamError{Cmd: "CGF", Param: "Latitude", Value: float32(91)}, err)
+
+	cgf.Latitude = 37
+	cgf.Radius = 100000
+	_, err = cgf.Command()
+	assert.Equal(t, &st.ParamError{Cmd: "CGF", Param: "Radius", Value: 100000}, err)
+}
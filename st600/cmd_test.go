@@ -0,0 +1,250 @@
+package st600
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCMDCommand(t *testing.T) {
+	testCases := []struct {
+		cmd      ST600CMD
+		expected string
+	}{
+		{
+			cmd:      ST600CMD{DevID: "100850000", Cmd: st.Cmd{Action: st.ResetCmd}},
+			expected: "ST600CMD;100850000;02;Reset",
+		},
+		{
+			cmd:      ST600CMD{DevID: "100850000", Cmd: st.Cmd{Action: st.PresetACmd}},
+			expected: "ST600CMD;100850000;02;Preset A",
+		},
+		{
+			cmd:      ST600CMD{DevID: "100850000", Cmd: st.Cmd{Action: st.SetOdometerCmd, Value: "999999999"}},
+			expected: "ST600CMD;100850000;02;SetOdometer=999999999",
+		},
+		{
+			cmd:      ST600CMD{DevID: "100850000", Cmd: st.Cmd{Action: st.SetSMSNoOfPanicCmd, Value: "01988888888"}},
+			expected: "ST600CMD;100850000;02;SetSMSNoOfPanic=01988888888",
+		},
+	}
+	for _, tc := range testCases {
+		frame, err := tc.cmd.Command()
+		require.Nil(t, err)
+		assert.Equal(t, tc.expected, string(frame))
+
+		// the built frame parses back to the same command
+		msg := parseCmd(t, tc.expected+"\r")
+		tc.cmd.SwVer = st.CmdVersion
+		assert.Equal(t, &tc.cmd, msg.CMD)
+	}
+}
+
+func TestCMDCommandErrors(t *testing.T) {
+	testCases := []struct {
+		cmd   st.Cmd
+		param string
+	}{
+		{cmd: st.Cmd{Action: st.SetOdometerCmd}, param: "SetOdometer"},
+		{cmd: st.Cmd{Action: st.SetOdometerCmd, Value: "A"}, param: "SetOdometer"},
+		{cmd: st.Cmd{Action: st.ResetCmd, Value: "1"}, param: "Reset"},
+		{cmd: st.Cmd{Action: st.SetOwnNoCmd, Value: "12;34"}, param: "SetOwnNo"},
+		{cmd: st.Cmd{Action: st.UnknownCmd}, param: "Action"},
+	}
+	for _, tc := range testCases {
+		cmd := ST600CMD{DevID: "100850000", Cmd: tc.cmd}
+		_, err := cmd.Command()
+		require.IsType(t, &st.ParamError{}, err, "%+v", tc.cmd)
+		assert.Equal(t, tc.param, err.(*st.ParamError).Param)
+	}
+}
+
+func TestCMDResponse(t *testing.T) {
+	testCases := []struct {
+		frame    string
+		expected st.CmdResult
+	}{
+		{
+			frame: "ST600CMD;Res;100850000;010;ReqIMSI;724031111553779\r",
+			expected: st.CmdResult{
+				Cmd:    st.Cmd{Action: st.ReqIMSICmd, Value: "724031111553779"},
+				IMSI:   "724031111553779",
+				Params: []string{"724031111553779"},
+			},
+		},
+		{
+			frame: "ST600CMD;Res;100850000;010;ReqVer;ST600E_SAMPLE_STBASE_001\r",
+			expected: st.CmdResult{
+				Cmd:     st.Cmd{Action: st.ReqVerCmd, Value: "ST600E_SAMPLE_STBASE_001"},
+				Version: "ST600E_SAMPLE_STBASE_001",
+				Params:  []string{"ST600E_SAMPLE_STBASE_001"},
+			},
+		},
+		{
+			frame: "ST600CMD;Res;100850000;010;Enable2NoUse\r",
+			expected: st.CmdResult{
+				Cmd:    st.Cmd{Action: st.Enable2Cmd},
+				NoUse:  true,
+				Params: []string{},
+			},
+		},
+		{
+			frame: "ST600CMD;Res;100850000;010;ReqSMSNoOfPanic;NoData\r",
+			expected: st.CmdResult{
+				Cmd:    st.Cmd{Action: st.ReqSMSNoOfPanicCmd},
+				NoData: true,
+				Params: []string{"NoData"},
+			},
+		},
+		{
+			frame: "ST600CMD;Res;100850000;010;SetHmeter=999999\r",
+			expected: st.CmdResult{
+				Cmd:    st.Cmd{Action: st.SetHMeterCmd, Value: "999999"},
+				Params: []string{},
+			},
+		},
+		{
+			frame: "ST600CMD;Res;100850000;259; ImproveBatteryLife =1\r",
+			expected: st.CmdResult{
+				Cmd:    st.Cmd{Action: st.ImproveBatteryLifeCmd, Value: "1"},
+				Params: []string{},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		msg := parseCmd(t, tc.frame)
+		assert.Equal(t, CMD, msg.Type)
+		require.NotNil(t, msg.CMD)
+		assert.True(t, msg.CMD.Resp)
+		assert.Equal(t, "100850000", msg.CMD.DevID)
+		assert.Equal(t, tc.expected, *msg.CMD.Result, tc.frame)
+		assert.Equal(t, tc.expected.Cmd, msg.CMD.Cmd)
+	}
+}
+
+func TestCMDResponseExtraInfo(t *testing.T) {
+	msg := parseCmd(t, "ST600CMD;Res;100850000;010;Enable1;20140213;05:16:03;04f54;+37.479630;+126.886010;000.000;000.00;0;0;3095;13.43;000000;1\r")
+	require.NotNil(t, msg.CMD.Result)
+	assert.Equal(t, st.Enable1Cmd, msg.CMD.Action)
+	assert.Equal(t, "", msg.CMD.Value)
+
+	extra := msg.CMD.Result.Extra
+	require.NotNil(t, extra)
+	assert.Equal(t, time.Date(2014, 2, 13, 5, 16, 3, 0, time.UTC), extra.Timestamp)
+	assert.Equal(t, "04f54", extra.Cell)
+	assert.InDelta(t, 37.479630, extra.Latitude, 0.000001)
+	assert.InDelta(t, 126.886010, extra.Longitude, 0.000001)
+	assert.Equal(t, float32(0), extra.Speed)
+	assert.Equal(t, uint8(0), extra.Satellites)
+	assert.False(t, extra.GPSFixed)
+	assert.Equal(t, uint32(3095), extra.Distance)
+	assert.Equal(t, float32(13.43), extra.PowerVolt)
+	assert.Equal(t, "000000", extra.IO)
+	assert.Equal(t, st.ParkingMode, extra.Mode)
+
+	actions := map[string]st.CmdAction{
+		"Disable1": st.Disable1Cmd,
+		"Enable2":  st.Enable2Cmd,
+		"Disable2": st.Disable2Cmd,
+	}
+	for name, action := range actions {
+		msg = parseCmd(t, "ST600CMD;Res;100850000;010;"+name+";20140213;05:16:03;04f54;+37.479630;+126.886010;000.000;000.00;0;0;3095;13.43;000000;1\r")
+		assert.Equal(t, action, msg.CMD.Action, name)
+		assert.Equal(t, extra, msg.CMD.Result.Extra, name)
+	}
+}
+
+func TestCMDResponseWithoutSwVer(t *testing.T) {
+	msg := parseCmd(t, "ST600CMD;Res;100850000;CircleGeoReq;NoData\r")
+	assert.Equal(t, "", msg.CMD.SwVer)
+	assert.Equal(t, st.ReqCircleGeoCmd, msg.CMD.Action)
+	assert.True(t, msg.CMD.Result.NoData)
+}
+
+func TestParseCMDSpec(t *testing.T) {
+	f, err := os.Open("ascii_spec.txt")
+	require.Nil(t, err)
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		line = strings.TrimPrefix(line, "[command] ")
+		line = strings.TrimPrefix(line, "[response] ")
+		// the responses with extra info are written as "[EXTRA_INFO]" in the spec (see TestCMDResponseExtraInfo)
+		if !strings.HasPrefix(line, "ST600CMD;") || strings.HasSuffix(line, ";[EXTRA_INFO]") {
+			continue
+		}
+		msg := parseCmd(t, line+"\r")
+		require.NotNil(t, msg.CMD, line)
+		assert.NotEqual(t, st.UnknownCmd, msg.CMD.Action, line)
+		count++
+	}
+	require.Nil(t, scanner.Err())
+	assert.NotZero(t, count)
+}
+
+func TestCMDPreset(t *testing.T) {
+	msg := parseCmd(t, "ST600CMD;Res;100850000;010;PresetA;NTW;0;internet;;;111.111.111.111;8600;;;;1234;RPT;60;180;120;60;3;0;0;;;;EVT;1;60;0;3;2;2;30;20;20;1;1;1;0;0;0;0;0;0;0;0;0;0;0;0;GSM;0;;;;;0;;;;;;;;SVC;1;120;0;0;0;0;1;1;1;0;0;0;0;ADP;U;T;2;9000;0;0;0;0;0;0;MSR;10;0.10;0.10;0.70;MBV;9.43;21.07;17.07;8.00;18.00;0.00;0.00;NPT;02;0;0;0;0;0;500;300;5;10;5;10;DEV;0;0;0;0;0;0;0;0\r")
+	assert.Equal(t, st.PresetACmd, msg.CMD.Action)
+	cfg := msg.CMD.Result.Config
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, &st.NTWParams{
+		Auth:       "0",
+		APN:        "internet",
+		ServerIP:   "111.111.111.111",
+		ServerPort: 8600,
+		PINNo:      "1234",
+	}, cfg.NTW)
+	assert.Equal(t, &st.RPTParams{
+		ParkingInterval:   180,
+		DrivingInterval:   120,
+		EmergencyInterval: 60,
+		EmergencyCount:    3,
+	}, cfg.RPT)
+	assert.Equal(t, "60", cfg.Sections["RPT"][0])
+	assert.Equal(t, 60, cfg.EVT.ParkingTime)
+	assert.Equal(t, [3]int{3, 2, 2}, cfg.EVT.InType)
+	assert.Equal(t, &st.GSMParams{}, cfg.GSM)
+	assert.Equal(t, 120, cfg.SVC.ParkingTime)
+	assert.Equal(t, "U", cfg.ADP.MainProtocol)
+	assert.Equal(t, 9000, cfg.ADP.Port)
+	assert.Equal(t, 10, cfg.MSR.Interval)
+	assert.Equal(t, float32(21.07), cfg.MBV.Levels[1])
+	// the NPT section is partial
+	assert.Nil(t, cfg.NPT)
+	assert.Equal(t, []string{"02", "0", "0", "0", "0", "0", "500", "300", "5", "10", "5", "10"}, cfg.Sections["NPT"])
+	assert.Equal(t, &st.DEVParams{Params: make([]int, 8)}, cfg.DEV)
+
+	// Preset doesn't send the ADP, MSR, MBV and NPT sections
+	msg = parseCmd(t, "ST600CMD;Res;100850000;010;Preset;NTW;0;internet;;;111.111.111.111;8600;;;;1234;RPT;60;180;120;60;3;0;0;;;;EVT;1;60;0;3;2;2;30;20;20;1;1;1;0;0;0;0;0;0;0;0;0;0;0;0;GSM;0;;;;;0;;;;;;;;SVC;1;120;0;0;0;0;1;1;1;0;0;0;0;DEV;0;0;0;0\r")
+	cfg = msg.CMD.Result.Config
+	require.NotNil(t, cfg)
+	assert.NotNil(t, cfg.SVC)
+	assert.Nil(t, cfg.ADP)
+	assert.Nil(t, cfg.NPT)
+	assert.Len(t, cfg.DEV.Params, 4)
+}
+
+func TestCMDPresetErrors(t *testing.T) {
+	frames := []string{
+		"ST600CMD;Res;100850000;010;Preset;0;internet\r",
+		"ST600CMD;Res;100850000;010;Preset;RPT;60;180;120\r",
+		"ST600CMD;Res;100850000;010;Preset;DEV;0;0;DEV;0;0\r",
+		"ST600CMD;Res;100850000;010;Preset;MSR;10;A;0.10;0.70\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		assert.NotNil(t, p.Msg().ParsingError, frame)
+		assert.Equal(t, frame, string(p.Msg().Frame))
+	}
+}
@@ -0,0 +1,120 @@
+package st600
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	testCases := []struct {
+		cmd interface {
+			Command() ([]byte, error)
+		}
+		expected string
+	}{
+		{
+			cmd: &ST600RPT{
+				DevID: "100850000",
+				RPTParams: st.RPTParams{
+					ParkingInterval:   180,
+					DrivingInterval:   120,
+					EmergencyInterval: 60,
+					EmergencyCount:    3,
+				},
+			},
+			expected: "ST600RPT;100850000;02;180;120;60;3;0;0;0;0;0",
+		},
+		{
+			cmd: &ST600MSR{
+				DevID: "100852588",
+				MSRParams: st.MSRParams{
+					Interval:     600,
+					Acceleration: 0.04,
+					Deceleration: 0.04,
+					Impact:       0.7,
+				},
+			},
+			expected: "ST600MSR;100852588;02;600;0.04;0.04;0.7",
+		},
+		{
+			cmd: &ST600DPA{
+				DevID: "600850000",
+				DPAParams: st.DPAParams{
+					Mode:         1,
+					Acceleration: 30,
+					Braking:      100,
+					SharpTurn:    70,
+					OverSpeed:    100,
+				},
+			},
+			expected: "SA200DPA;600850000;02;1;0.0;30.0;100.0;70.0;100.0",
+		},
+		{
+			cmd: &ST600LTM{
+				DevID: "100850000",
+				LTMParams: st.LTMParams{
+					Enable: 1,
+					Mode:   2,
+				},
+			},
+			expected: "ST600LTM;100850000;02;1;2;0",
+		},
+	}
+	for _, tc := range testCases {
+		cmd, err := tc.cmd.Command()
+		require.Nil(t, err, tc.expected)
+		assert.Equal(t, tc.expected, string(cmd))
+		parseCmd(t, tc.expected+"\r")
+	}
+
+	_, err := (&ST600GSM{DevID: "100850000", GSMParams: st.GSMParams{SMSMode: 10}}).Command()
+	assert.Equal(t, &st.ParamError{Cmd: "GSM", Param: "SMSMode", Value: 10}, err)
+}
+
+func TestParseConfigResponses(t *testing.T) {
+	msg := parseCmd(t, "ST600RPT;Res;100850000;010;180;120;60;3;0;0;0;0;0\r")
+	assert.Equal(t, RPTCmd, msg.Type)
+	assert.Equal(t, &ST600RPT{
+		DevID: "100850000",
+		SwVer: "010",
+		RPTParams: st.RPTParams{
+			ParkingInterval:   180,
+			DrivingInterval:   120,
+			EmergencyInterval: 60,
+			EmergencyCount:    3,
+		},
+		Resp: true,
+	}, msg.RPT)
+
+	msg = parseCmd(t, "ST600EVT;Res;100850000;010;1;60;0;3;2;2;30;20;20;1;0;1;0;0;0;0;0;0;0;0;0;0;0;0\r")
+	assert.Equal(t, EVTCmd, msg.Type)
+	assert.Nil(t, msg.EVT)
+	require.NotNil(t, msg.EVTCmd)
+	assert.True(t, msg.EVTCmd.Resp)
+	assert.Equal(t, 60, msg.EVTCmd.ParkingTime)
+
+	msg = parseCmd(t, "ST600NPT;Res;100850000;010; 02;0;0;0;0;0;500;300;5;10;70;5;10;70;0\r")
+	assert.Equal(t, NPTCmd, msg.Type)
+	assert.Equal(t, st.NPTParams{
+		DisconnectTime:     500,
+		ConnectTime:        300,
+		SendCount:          5,
+		SendInterval:       10,
+		SendLimit:          70,
+		BackupSendCount:    5,
+		BackupSendInterval: 10,
+		BackupSendLimit:    70,
+	}, msg.NPT.NPTParams)
+
+	msg = parseCmd(t, "ST600GSM;Res;100850000;010;0;;;;;0;;;;;;;\r")
+	assert.Equal(t, GSMCmd, msg.Type)
+	assert.True(t, msg.GSM.Resp)
+
+	p := ParseString("ST600MSR;Res;100850000;010;600;A;0.04;0.7\r", ParserOpts{})
+	require.True(t, p.Next())
+	assert.Equal(t, MSRCmd, p.Msg().Type)
+	assert.IsType(t, &st.ParamError{}, p.Msg().ParsingError)
+}
@@ -29,6 +29,37 @@ type CommonReport struct {
 	IO         string
 }
 
+// common returns the fields of the report shared with the other models, given the backup voltage (a field of each
+// type of report).
+func (r *CommonReport) common(backupVolt float32) st.CommonReport {
+	cell := r.Cell.Cell2G
+	if r.Cell.Type == Cell3GType {
+		cell = r.Cell.CellID
+	}
+	return st.CommonReport{
+		DevID:      r.DevID,
+		Model:      r.Model,
+		SwVer:      r.SwVer,
+		Timestamp:  r.Timestamp,
+		Cell:       cell,
+		Latitude:   r.Latitude,
+		Longitude:  r.Longitude,
+		Speed:      r.Speed,
+		Course:     r.Course,
+		Satellites: r.Satellites,
+		GPSFixed:   r.GPSFixed,
+		Distance:   r.Distance,
+		IO:         r.IO,
+		PowerVolt:  r.PowerVolt,
+		BackupVolt: backupVolt,
+	}
+}
+
+// KnownModel tells if the reports of the model are decoded by this package.
+func KnownModel(model st.Model) bool {
+	return knownModel(model)
+}
+
 func knownModel(model st.Model) bool {
 	switch model {
 	case st.ST600V, st.ST600R:
@@ -182,3 +213,149 @@ func parseCommonAscii(lex *lexer.Lexer, msg *Msg, cmn *CommonReport) {
 
 	return
 }
+
+// writeAsciiCommon writes the header and the common fields of an ascii report.
+func writeAsciiCommon(w *st.AsciiWriter, hdr string, cmn *CommonReport) error {
+	w.Hdr(hdr)
+	w.DevID(cmn.DevID)
+	w.Model(cmn.Model)
+	w.SwVer(cmn.SwVer)
+	w.Timestamp(cmn.Timestamp)
+	if err := writeAsciiCell(w, cmn.Cell); err != nil {
+		return err
+	}
+	w.Lat(cmn.Latitude)
+	w.Lon(cmn.Longitude)
+	w.Speed(cmn.Speed)
+	w.Course(cmn.Course)
+	w.Satellites(cmn.Satellites)
+	w.Fix(cmn.GPSFixed)
+	w.Distance(cmn.Distance)
+	w.PowerVolt(cmn.PowerVolt)
+	w.IO(cmn.IO)
+	return w.Err()
+}
+
+// zipIOBits is the number of IO bits reported by a ST600 (the same count of chars of the ascii IO field).
+const zipIOBits = 8
+
+func parseCommonZip(lex *lexer.Lexer, msg *Msg, cmn *CommonReport, cell3G bool) {
+	devID, err := st.ZipDevID(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.DevID = devID
+
+	model, err := st.ZipModel(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	msg.Model = model
+	cmn.Model = model
+	if !knownModel(model) {
+		msg.ParsingError = st.ErrUnsupportedModel
+		return
+	}
+
+	swVer, err := st.ZipSwVer(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.SwVer = swVer
+
+	ts, err := st.ZipTimestamp(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.Timestamp = ts
+
+	cell, err := zipCell(lex, cell3G)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.Cell = cell
+
+	lat, err := st.ZipLat(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.Latitude = lat
+
+	lon, err := st.ZipLon(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.Longitude = lon
+
+	speed, err := st.ZipSpeed(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.Speed = speed
+
+	course, err := st.ZipCourse(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.Course = course
+
+	satellites, fix, err := st.ZipSatFix(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.Satellites = satellites
+	cmn.GPSFixed = fix
+
+	distance, err := st.ZipDistance(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.Distance = distance
+
+	powerVolt, err := st.ZipPowerVolt(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.PowerVolt = powerVolt
+
+	ioStatus, err := st.ZipIO(lex, zipIOBits)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	cmn.IO = ioStatus
+
+	return
+}
+
+// writeZipCommon writes the common fields of a zip report, as read by parseCommonZip.
+func writeZipCommon(w *st.ZipWriter, cmn *CommonReport) error {
+	w.DevID(cmn.DevID)
+	w.Model(cmn.Model)
+	w.SwVer(cmn.SwVer)
+	w.Timestamp(cmn.Timestamp)
+	if err := writeZipCell(w, cmn.Cell); err != nil {
+		return err
+	}
+	w.Lat(cmn.Latitude)
+	w.Lon(cmn.Longitude)
+	w.Speed(cmn.Speed)
+	w.Course(cmn.Course)
+	w.SatFix(cmn.Satellites, cmn.GPSFixed)
+	w.Distance(cmn.Distance)
+	w.PowerVolt(cmn.PowerVolt)
+	w.IO(cmn.IO)
+	return w.Err()
+}
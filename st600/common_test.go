@@ -30,3 +30,52 @@ func equalCommon(t *testing.T, expected *CommonReport, actual *CommonReport) {
 	assert.Equal(t, expected.PowerVolt, actual.PowerVolt)
 	assert.Equal(t, expected.IO, actual.IO)
 }
+
+func TestCommon(t *testing.T) {
+	c := testEMG.Common()
+	assert.Equal(t, "205951725", c.DevID)
+	assert.Equal(t, testEMG.Model, c.Model)
+	assert.Equal(t, testEMG.SwVer, c.SwVer)
+	assert.Equal(t, testEMG.Timestamp, c.Timestamp)
+	assert.Equal(t, "001cbf75", c.Cell)
+	assert.Equal(t, testEMG.Latitude, c.Latitude)
+	assert.Equal(t, testEMG.Longitude, c.Longitude)
+	assert.Equal(t, testEMG.Distance, c.Distance)
+	assert.Equal(t, testEMG.IO, c.IO)
+	assert.Equal(t, testEMG.PowerVolt, c.PowerVolt)
+	assert.Equal(t, testEMG.BackupVolt, c.BackupVolt)
+
+	r := testEMG
+	r.Cell = Cell{Type: Cell2GType, Cell2G: "00100"}
+	assert.Equal(t, "00100", r.Common().Cell)
+}
+
+func TestMsgReport(t *testing.T) {
+	frames := []string{
+		"ST600STT;100850000;20;010;20081017;07:41:56;00100;+37.478519;+126.886819;000.012;000.00;9;1;0;15.30;00110000;1;0072;0;4.5;1;12.35\r",
+		"ST600ALV;600850777\r",
+		"ST600CMD;100850000;02;Reset\r",
+	}
+	var stream string
+	for _, f := range frames {
+		stream += f
+	}
+	p := ParseString(stream, ParserOpts{})
+
+	require.True(t, p.Next())
+	r := p.Msg().Report()
+	require.NotNil(t, r)
+	c := r.Common()
+	assert.Equal(t, "100850000", c.DevID)
+	assert.Equal(t, "00100", c.Cell)
+	assert.Equal(t, float32(15.30), c.PowerVolt)
+	assert.EqualValues(t, 4.5, c.BackupVolt)
+
+	require.True(t, p.Next())
+	assert.Equal(t, ALVReport, p.Msg().Type)
+	assert.Nil(t, p.Msg().Report())
+
+	require.True(t, p.Next())
+	assert.Nil(t, p.Msg().ParsingError)
+	assert.Nil(t, p.Msg().Report())
+}
@@ -0,0 +1,65 @@
+package st600
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600CTR controls the counter of a device (CTR command). The command sets a Value ("1"), or reads it ("R;1"). The
+// replies of a read don't have Res, and carry the value after the R (like "R0" or "R1").
+type ST600CTR struct {
+	DevID string
+	SwVer string
+
+	// Read is true for a read command, and for its replies
+	Read  bool
+	Value int
+
+	// Resp is true when this is a response (or a reply of a read)
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR). An empty SwVer is sent as st.CmdVersion.
+func (ctr *ST600CTR) Command() ([]byte, error) {
+	if ctr.Value < 0 || ctr.Value > 9 {
+		return nil, &st.ParamError{Cmd: "CTR", Param: "Value", Value: ctr.Value}
+	}
+	return st.Command("ST600CTR", ctr.DevID, ctr.SwVer, st.ParamsFunc(func(w *st.AsciiWriter) {
+		if ctr.Read {
+			w.Field("R")
+		}
+		w.Field(strconv.Itoa(ctr.Value))
+	}))
+}
+
+func parseCTR(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = CTRCmd
+
+	ctr := &ST600CTR{}
+	msg.CTR = ctr
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	ctr.Resp, ctr.DevID, ctr.SwVer = resp, devID, swVer
+
+	var err error
+	switch {
+	case len(fields) == 2 && fields[0] == "R":
+		ctr.Read = true
+		ctr.Value, err = atoi("CTR", "Value", fields[1])
+	case len(fields) == 1 && len(fields[0]) > 1 && strings.HasPrefix(fields[0], "R"):
+		ctr.Read, ctr.Resp = true, true
+		ctr.Value, err = atoi("CTR", "Value", fields[0][1:])
+	case len(fields) == 1:
+		ctr.Value, err = atoi("CTR", "Value", fields[0])
+	default:
+		err = fmt.Errorf("invalid CTR parameters count: %d", len(fields))
+	}
+	msg.ParsingError = err
+}
@@ -0,0 +1,86 @@
+package st600
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600DEX sends data to the serial peripheral attached to a device (DEX command), the reverse of the UEX report.
+// The response confirms the data received by the device.
+type ST600DEX struct {
+	DevID    string
+	SwVer    string
+	Len      uint16
+	Data     []byte
+	Checksum uint8
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Valid tells if the checksum matches the data.
+func (dex *ST600DEX) Valid() bool {
+	return checksum(dex.Data) == dex.Checksum
+}
+
+// Command builds the frame of the command (without the CR), with the length and the checksum of the data (Len and
+// Checksum are ignored). The data can't have a CR. An empty SwVer is sent as st.CmdVersion.
+func (dex *ST600DEX) Command() ([]byte, error) {
+	if len(dex.Data) == 0 || bytes.IndexByte(dex.Data, st.EndOfFrame) >= 0 {
+		return nil, &st.ParamError{Cmd: "DEX", Param: "Data", Value: string(dex.Data)}
+	}
+	return st.Command("ST600DEX", dex.DevID, dex.SwVer, st.ParamsFunc(func(w *st.AsciiWriter) {
+		w.Data(dex.Data)
+		w.Checksum(checksum(dex.Data))
+	}))
+}
+
+func parseDEX(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = DEXReport
+
+	dex := &ST600DEX{}
+	msg.DEX = dex
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	dex.Resp, dex.DevID, dex.SwVer = resp, devID, swVer
+
+	if len(fields) < 3 {
+		msg.ParsingError = fmt.Errorf("invalid DEX parameters count: %d", len(fields))
+		return
+	}
+	length, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		msg.ParsingError = &st.ParamError{Cmd: "DEX", Param: "Len", Value: fields[0]}
+		return
+	}
+	dex.Len = uint16(length)
+
+	// the data could have separators, so it's everything between the length and the checksum (the length isn't
+	// used, as some responses don't have the one of the data, like the spec example)
+	dex.Data = []byte(strings.Join(fields[1:len(fields)-1], string(st.Separator)))
+
+	chk := fields[len(fields)-1]
+	sum, err := strconv.ParseUint(chk, 16, 8)
+	if err != nil || len(chk) != 2 {
+		msg.ParsingError = &st.ParamError{Cmd: "DEX", Param: "Checksum", Value: chk}
+		return
+	}
+	dex.Checksum = uint8(sum)
+}
+
+// checksum is the sum of the bytes of the external data (UEX and DEX), truncated to a byte.
+func checksum(data []byte) uint8 {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return sum
+}
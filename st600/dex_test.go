@@ -0,0 +1,62 @@
+package st600
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDEXCommand(t *testing.T) {
+	dex := ST600DEX{DevID: "100850000", Data: []byte("012345")}
+	b, err := dex.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST600DEX;100850000;02;6;012345;2F", string(b))
+
+	msg := parseCmd(t, string(b)+"\r")
+	assert.Equal(t, DEXReport, msg.Type)
+	assert.Equal(t, &ST600DEX{DevID: "100850000", SwVer: "02", Len: 6, Data: []byte("012345"), Checksum: 0x2F}, msg.DEX)
+	assert.True(t, msg.DEX.Valid())
+
+	// the data can have separators
+	dex.Data = []byte("A;B;C")
+	b, err = dex.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST600DEX;100850000;02;5;A;B;C;3C", string(b))
+	msg = parseCmd(t, string(b)+"\r")
+	assert.Equal(t, []byte("A;B;C"), msg.DEX.Data)
+	assert.True(t, msg.DEX.Valid())
+
+	for _, data := range []string{"", "A\rB"} {
+		dex.Data = []byte(data)
+		_, err = dex.Command()
+		assert.IsType(t, &st.ParamError{}, err)
+	}
+}
+
+func TestDEXResponse(t *testing.T) {
+	msg := parseCmd(t, "ST600DEX;Res;100850000;010;25;012345;2F\r")
+	require.NotNil(t, msg.DEX)
+	assert.True(t, msg.DEX.Resp)
+	assert.Equal(t, "010", msg.DEX.SwVer)
+	assert.Equal(t, []byte("012345"), msg.DEX.Data)
+	assert.True(t, msg.DEX.Valid())
+
+	msg = parseCmd(t, "ST600DEX;Res;100850000;010;6;012345;2E\r")
+	assert.False(t, msg.DEX.Valid())
+}
+
+func TestDEXErrors(t *testing.T) {
+	frames := []string{
+		"ST600DEX;Res;100850000;010;6;012345\r",
+		"ST600DEX;Res;100850000;010;A;012345;2F\r",
+		"ST600DEX;Res;100850000;010;6;012345;2G\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		assert.NotNil(t, p.Msg().ParsingError, frame)
+		assert.Equal(t, frame, string(p.Msg().Frame))
+	}
+}
@@ -0,0 +1,38 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600DPA holds the driver pattern analysis parameters of a device (DPA command), the thresholds of the DPA alerts.
+// The header of this command is "SA200DPA".
+type ST600DPA struct {
+	DevID string
+	SwVer string
+	st.DPAParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (dpa *ST600DPA) Command() ([]byte, error) {
+	return st.Command("SA200DPA", dpa.DevID, dpa.SwVer, &dpa.DPAParams)
+}
+
+func parseDPA(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = DPACmd
+
+	dpa := &ST600DPA{}
+	msg.DPA = dpa
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	dpa.Resp, dpa.DevID, dpa.SwVer = resp, devID, swVer
+
+	msg.ParsingError = dpa.DecodeParams(fields)
+}
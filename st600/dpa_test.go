@@ -0,0 +1,65 @@
+package st600
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDPA(t *testing.T) {
+	msg := parseCmd(t, "SA200DPA;Res;600850000;010;1;0.0;30.0;100.0;70.0;100.0\r")
+	assert.Equal(t, DPACmd, msg.Type)
+	expected := &ST600DPA{
+		DevID: "600850000",
+		SwVer: "010",
+		DPAParams: st.DPAParams{
+			Mode:         1,
+			Acceleration: 30,
+			Braking:      100,
+			SharpTurn:    70,
+			OverSpeed:    100,
+		},
+		Resp: true,
+	}
+	assert.Equal(t, expected, msg.DPA)
+
+	threshold, ok := msg.DPA.Threshold(st.SharpTurnFromDPAAlt)
+	assert.True(t, ok)
+	assert.Equal(t, float32(70), threshold)
+	_, ok = msg.DPA.Threshold(st.JammingDetectedAlt)
+	assert.False(t, ok)
+
+	// the built command parses back to the same parameters
+	b, err := msg.DPA.Command()
+	require.Nil(t, err)
+	msg = parseCmd(t, string(b)+"\r")
+	assert.False(t, msg.DPA.Resp)
+	assert.Equal(t, expected.DPAParams, msg.DPA.DPAParams)
+}
+
+func TestLTM(t *testing.T) {
+	msg := parseCmd(t, "ST600LTM;Res;100850000;010;1;2;0\r")
+	assert.Equal(t, LTMCmd, msg.Type)
+	assert.Equal(t, &ST600LTM{
+		DevID:     "100850000",
+		SwVer:     "010",
+		LTMParams: st.LTMParams{Enable: 1, Mode: 2},
+		Resp:      true,
+	}, msg.LTM)
+}
+
+func TestDPAErrors(t *testing.T) {
+	frames := []string{
+		"SA200DPA;600850000;02;1;0.0;30.0\r",
+		"SA200DPA;600850000;02;1;0.0;A;100.0;70.0;100.0\r",
+		"ST600LTM;100850000;02;1;2\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		assert.NotNil(t, p.Msg().ParsingError, frame)
+		assert.Equal(t, frame, string(p.Msg().Frame))
+	}
+}
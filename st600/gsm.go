@@ -0,0 +1,37 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600GSM holds the GSM parameters of a device (GSM command).
+type ST600GSM struct {
+	DevID string
+	SwVer string
+	st.GSMParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (gsm *ST600GSM) Command() ([]byte, error) {
+	return st.Command("ST600GSM", gsm.DevID, gsm.SwVer, &gsm.GSMParams)
+}
+
+func parseGSM(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = GSMCmd
+
+	gsm := &ST600GSM{}
+	msg.GSM = gsm
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	gsm.Resp, gsm.DevID, gsm.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &gsm.GSMParams)
+}
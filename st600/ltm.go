@@ -0,0 +1,37 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600LTM holds the LTM parameters of a device.
+type ST600LTM struct {
+	DevID string
+	SwVer string
+	st.LTMParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (ltm *ST600LTM) Command() ([]byte, error) {
+	return st.Command("ST600LTM", ltm.DevID, ltm.SwVer, &ltm.LTMParams)
+}
+
+func parseLTM(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = LTMCmd
+
+	ltm := &ST600LTM{}
+	msg.LTM = ltm
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	ltm.Resp, ltm.DevID, ltm.SwVer = resp, devID, swVer
+
+	msg.ParsingError = ltm.DecodeParams(fields)
+}
@@ -0,0 +1,47 @@
+package st600
+
+import (
+	"bytes"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600NPT holds the NPT parameters of a device.
+type ST600NPT struct {
+	DevID string
+	SwVer string
+	st.NPTParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (npt *ST600NPT) Command() ([]byte, error) {
+	return st.Command("ST600NPT", npt.DevID, npt.SwVer, &npt.NPTParams)
+}
+
+func parseNPT(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = NPTCmd
+
+	npt := &ST600NPT{}
+	msg.NPT = npt
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	npt.Resp, npt.DevID, npt.SwVer = resp, devID, swVer
+
+	b, ok := asciiParams(lex, msg)
+	if !ok {
+		return
+	}
+	if resp && bytes.Count(b, []byte{st.Separator}) == st.NPTParamsCount {
+		// some responses repeat the version of the command (like " 02") before the parameters
+		b = b[bytes.IndexByte(b, st.Separator)+1:]
+	}
+	msg.ParsingError = npt.DecodeParams(st.SplitParams(b))
+}
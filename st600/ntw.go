@@ -0,0 +1,37 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600NTW holds the network parameters of a device (NTW command).
+type ST600NTW struct {
+	DevID string
+	SwVer string
+	st.NTWParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (ntw *ST600NTW) Command() ([]byte, error) {
+	return st.Command("ST600NTW", ntw.DevID, ntw.SwVer, &ntw.NTWParams)
+}
+
+func parseNTW(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = NTWCmd
+
+	ntw := &ST600NTW{}
+	msg.NTW = ntw
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	ntw.Resp, ntw.DevID, ntw.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &ntw.NTWParams)
+}
@@ -0,0 +1,77 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// parseCmdHdr reads the start of a configuration command ("DevID;Ver;") or of its response ("Res;DevID;SwVer;").
+// Returns false if there was an error (saved in msg.ParsingError).
+func parseCmdHdr(lex *lexer.Lexer, msg *Msg) (resp bool, devID string, swVer string, ok bool) {
+	// Res and DevID, or just DevID
+	isDevID, devID, token, err := st.AsciiDevIDOrRes(lex)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	if !isDevID {
+		resp = true
+
+		devID, token, err = st.AsciiDevID(lex)
+		msg.Frame = append(msg.Frame, token.Literal...)
+		if err != nil {
+			msg.ParsingError = err
+			return
+		}
+	}
+
+	swVer, token, err = st.AsciiSwVer2(lex)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	ok = true
+	return
+}
+
+// maxParamsLen is the maximum length of the parameters of a command (or of its response).
+const maxParamsLen = 512
+
+// asciiParams reads the rest of the frame (up to the CR), returning the parameters without the CR. Returns false
+// if there was an error (saved in msg.ParsingError).
+func asciiParams(lex *lexer.Lexer, msg *Msg) ([]byte, bool) {
+	token, err := lex.Next(maxParamsLen, st.EndOfFrame)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = err
+		return nil, false
+	}
+	return token.WithoutSuffix(), true
+}
+
+// parseParams reads the parameters of a command, decoding them into params.
+func parseParams(lex *lexer.Lexer, msg *Msg, params st.ParamsDecoder) {
+	b, ok := asciiParams(lex, msg)
+	if !ok {
+		return
+	}
+	msg.ParsingError = params.DecodeParams(st.SplitParams(b))
+}
+
+// parseCmdFields reads the rest of the frame (up to the CR) at once, decoding the start of a command ("DevID;Ver;")
+// or of its response ("Res;DevID;SwVer;") and returning the remaining fields (see st.DecodeCmdHdr). Returns false if
+// there was an error (saved in msg.ParsingError).
+func parseCmdFields(lex *lexer.Lexer, msg *Msg) (resp bool, devID string, swVer string, fields []string, ok bool) {
+	b, ok := asciiParams(lex, msg)
+	if !ok {
+		return
+	}
+	resp, devID, swVer, fields, err := st.DecodeCmdHdr(st.SplitParams(b))
+	if err != nil {
+		msg.ParsingError = err
+		ok = false
+	}
+	return
+}
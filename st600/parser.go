@@ -2,6 +2,7 @@
 package st600
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
@@ -23,9 +24,11 @@ type ParserOpts struct {
 
 // Parse returns a Parser to parse the content of a reader.
 func Parse(r io.Reader, opts ParserOpts) *Parser {
+	rd := bufio.NewReader(r)
 	return &Parser{
+		rd: rd,
 		lex: &lexer.Lexer{
-			Reader: r,
+			Reader: rd,
 		},
 		opts: opts,
 	}
@@ -41,6 +44,7 @@ func ParseBytes(b []byte, opts ParserOpts) *Parser {
 
 // Parser is a ST300/ST340 parser
 type Parser struct {
+	rd   *bufio.Reader
 	lex  *lexer.Lexer
 	opts ParserOpts
 
@@ -48,8 +52,11 @@ type Parser struct {
 	err  error
 }
 
+// Next parses the next frame, returning false at the end of the underlying reader or when a read error occurs.
+// Bytes that don't belong to any frame (like the tail of a malformed frame) are skipped until the start of the next
+// plausible frame, and returned as a Msg with a st.ErrSkippedBytes ParsingError.
 func (p *Parser) Next() bool {
-	token, err := p.lex.NextFixed(1)
+	b, err := p.rd.Peek(1)
 	if err != nil {
 		if err != io.EOF {
 			p.err = err
@@ -57,17 +64,51 @@ func (p *Parser) Next() bool {
 		return false
 	}
 
-	switch token.Literal[0] {
-	case st.STX:
-		p.err = st.ErrZipUnsupported
-		return false
-	case 'S':
+	switch {
+	case b[0] == st.STX && st.ZipAhead(p.rd):
+		p.rd.Discard(1)
+		p.last = p.parseZip()
+		return true
+	case b[0] == 'S':
+		p.rd.Discard(1)
 		p.last = p.parseAscii()
 		return true
 	default:
-		p.err = fmt.Errorf("unexpected byte: %v", token.Literal[0])
+		p.last = p.skip()
+		return p.err == nil
+	}
+}
+
+// skip consumes the bytes until the start of the next plausible frame.
+func (p *Parser) skip() *Msg {
+	skipped, err := st.SkipToFrame(p.rd, p.frameAhead)
+	if err != nil {
+		p.err = err
+	}
+	return &Msg{
+		Frame:        skipped,
+		ParsingError: st.ErrSkippedBytes,
+	}
+}
+
+// frameAhead tells if the next bytes are the start of a zip frame or of an ascii frame with a known HDR (or any HDR,
+// when unknown frames are skipped).
+func (p *Parser) frameAhead() bool {
+	if st.ZipAhead(p.rd) {
+		return true
+	}
+	if !st.AsciiHdrAhead(p.rd) {
 		return false
 	}
+	if p.opts.SkipUnknownFrames {
+		return true
+	}
+	b, _ := p.rd.Peek(9)
+	lex := &lexer.Lexer{
+		Reader: bytes.NewReader(b[1:]),
+	}
+	token, _ := lex.NextFixed(8)
+	return asciiHdr(token) != UnknownMsg
 }
 
 func (p *Parser) Msg() *Msg {
@@ -103,6 +144,44 @@ func (p *Parser) parseAscii() *Msg {
 		parseALVAscii(p.lex, msg)
 	case UEXReport:
 		parseUEXAscii(p.lex, msg)
+	case CGFCmd:
+		parseCGF(p.lex, msg)
+	case NTWCmd:
+		parseNTW(p.lex, msg)
+	case RPTCmd:
+		parseRPT(p.lex, msg)
+	case GSMCmd:
+		parseGSM(p.lex, msg)
+	case SVCCmd:
+		parseSVC(p.lex, msg)
+	case MBVCmd:
+		parseMBV(p.lex, msg)
+	case MSRCmd:
+		parseMSR(p.lex, msg)
+	case ADPCmd:
+		parseADP(p.lex, msg)
+	case NPTCmd:
+		parseNPT(p.lex, msg)
+	case PLGCmd:
+		parsePLG(p.lex, msg)
+	case PLSCmd:
+		parsePLS(p.lex, msg)
+	case PLCCmd:
+		parsePLC(p.lex, msg)
+	case CMD:
+		parseCMD(p.lex, msg)
+	case DEXReport:
+		parseDEX(p.lex, msg)
+	case STRCmd:
+		parseSTR(p.lex, msg)
+	case GTRCmd:
+		parseGTR(p.lex, msg)
+	case CTRCmd:
+		parseCTR(p.lex, msg)
+	case DPACmd:
+		parseDPA(p.lex, msg)
+	case LTMCmd:
+		parseLTM(p.lex, msg)
 	default:
 		msg.ParsingError = ErrUnknownHdr
 	}
@@ -117,6 +196,58 @@ func (p *Parser) parseAscii() *Msg {
 	return msg
 }
 
+func (p *Parser) parseZip() *Msg {
+	msg := &Msg{}
+	msg.Frame = append(msg.Frame, st.STX)
+
+	length, token, err := st.ZipLen(p.lex)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = fmt.Errorf("error reading zip length: %s", err)
+		return msg
+	}
+
+	// the whole frame is read at once (plus the ETX), the fields are decoded later from its content
+	token, err = p.lex.NextFixed(int(length) + 1)
+	msg.Frame = append(msg.Frame, token.Literal...)
+	if err != nil {
+		msg.ParsingError = fmt.Errorf("error reading zip frame: %s", err)
+		return msg
+	}
+	if !token.EndsWith(st.ETX) {
+		msg.ParsingError = st.ErrInvalidETX
+		return msg
+	}
+	body := token.WithoutSuffix()
+	if len(body) == 0 {
+		msg.ParsingError = ErrUnknownHdr
+		return msg
+	}
+	lex := &lexer.Lexer{
+		Reader: bytes.NewReader(body[1:]),
+	}
+
+	cell3G := zipCell3G(body)
+	switch body[0] {
+	case st.ZipSTT:
+		parseSTTZip(lex, msg, cell3G)
+	case st.ZipEMG:
+		parseEMGZip(lex, msg, cell3G)
+	case st.ZipEVT:
+		parseEVTZip(lex, msg, cell3G)
+	case st.ZipALT:
+		parseALTZip(lex, msg, cell3G)
+	case st.ZipALV:
+		parseALVZip(lex, msg)
+	case st.ZipUEX:
+		parseUEXZip(lex, msg, cell3G)
+	default:
+		msg.ParsingError = ErrUnknownHdr
+	}
+
+	return msg
+}
+
 var (
 	sttHdr = []byte("T600STT;")
 	emgHdr = []byte("T600EMG;")
@@ -124,6 +255,25 @@ var (
 	altHdr = []byte("T600ALT;")
 	alvHdr = []byte("T600ALV;")
 	uexHdr = []byte("T600UEX;")
+	cgfHdr = []byte("T600CGF;")
+	ntwHdr = []byte("T600NTW;")
+	rptHdr = []byte("T600RPT;")
+	gsmHdr = []byte("T600GSM;")
+	svcHdr = []byte("T600SVC;")
+	mbvHdr = []byte("T600MBV;")
+	msrHdr = []byte("T600MSR;")
+	adpHdr = []byte("T600ADP;")
+	nptHdr = []byte("T600NPT;")
+	plgHdr = []byte("T600PLG;")
+	plsHdr = []byte("T600PLS;")
+	plcHdr = []byte("T600PLC;")
+	cmdHdr = []byte("T600CMD;")
+	dexHdr = []byte("T600DEX;")
+	strHdr = []byte("T600STR;")
+	gtrHdr = []byte("T600GTR;")
+	ctrHdr = []byte("T600CTR;")
+	dpaHdr = []byte("A200DPA;")
+	ltmHdr = []byte("T600LTM;")
 )
 
 func asciiHdr(token lexer.Token) MsgType {
@@ -143,6 +293,44 @@ func asciiHdr(token lexer.Token) MsgType {
 		return ALVReport
 	case bytes.Equal(token.Literal, uexHdr):
 		return UEXReport
+	case bytes.Equal(token.Literal, cgfHdr):
+		return CGFCmd
+	case bytes.Equal(token.Literal, ntwHdr):
+		return NTWCmd
+	case bytes.Equal(token.Literal, rptHdr):
+		return RPTCmd
+	case bytes.Equal(token.Literal, gsmHdr):
+		return GSMCmd
+	case bytes.Equal(token.Literal, svcHdr):
+		return SVCCmd
+	case bytes.Equal(token.Literal, mbvHdr):
+		return MBVCmd
+	case bytes.Equal(token.Literal, msrHdr):
+		return MSRCmd
+	case bytes.Equal(token.Literal, adpHdr):
+		return ADPCmd
+	case bytes.Equal(token.Literal, nptHdr):
+		return NPTCmd
+	case bytes.Equal(token.Literal, plgHdr):
+		return PLGCmd
+	case bytes.Equal(token.Literal, plsHdr):
+		return PLSCmd
+	case bytes.Equal(token.Literal, plcHdr):
+		return PLCCmd
+	case bytes.Equal(token.Literal, cmdHdr):
+		return CMD
+	case bytes.Equal(token.Literal, dexHdr):
+		return DEXReport
+	case bytes.Equal(token.Literal, strHdr):
+		return STRCmd
+	case bytes.Equal(token.Literal, gtrHdr):
+		return GTRCmd
+	case bytes.Equal(token.Literal, ctrHdr):
+		return CTRCmd
+	case bytes.Equal(token.Literal, dpaHdr):
+		return DPACmd
+	case bytes.Equal(token.Literal, ltmHdr):
+		return LTMCmd
 	default:
 		return UnknownMsg
 	}
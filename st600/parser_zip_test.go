@@ -2,9 +2,11 @@ package st600
 
 import (
 	"testing"
+	"time"
 
 	"github.com/larixsource/suntech/st"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // page 46, rev 1.00
@@ -32,8 +34,251 @@ var sttZipSpec = []byte{0x02,
 	0x0C, 0x35,
 	0x03}
 
-func TestParseST600ErrZipUnsupported(t *testing.T) {
+// zipFrame returns a copy of the spec frame, with the model replaced by ST600R and the given header, tail (the
+// fields after IO) and cell (nil to keep the 2G cell of the spec).
+func zipFrame(hdr byte, cell []byte, tail []byte) []byte {
+	body := []byte{hdr}
+	body = append(body, sttZipSpec[4:9]...)
+	body = append(body, byte(st.ST600R))
+	body = append(body, sttZipSpec[10:18]...)
+	if cell == nil {
+		cell = sttZipSpec[18:21]
+	}
+	body = append(body, cell...)
+	body = append(body, sttZipSpec[21:43]...)
+	body = append(body, tail...)
+
+	frame := []byte{st.STX, byte(len(body) >> 8), byte(len(body))}
+	frame = append(frame, body...)
+	return append(frame, st.ETX)
+}
+
+var zipCommon = CommonReport{
+	DevID:      "100850001",
+	Model:      st.ST600R,
+	SwVer:      10,
+	Timestamp:  time.Date(2008, 10, 17, 7, 41, 56, 0, time.UTC),
+	Cell:       Cell{Type: Cell2GType, Cell2G: "2F100"},
+	Latitude:   37.478519,
+	Longitude:  126.886819,
+	Speed:      32.51,
+	Course:     0,
+	Satellites: 9,
+	GPSFixed:   true,
+	Distance:   500,
+	PowerVolt:  15.3,
+	IO:         "00110000",
+}
+
+func TestParseST600SpecZip(t *testing.T) {
 	p := ParseBytes(sttZipSpec, ParserOpts{})
+	assert.True(t, p.Next())
+	assert.Nil(t, p.Error())
+	msg := p.Msg()
+	require.NotNil(t, msg)
+
+	// the spec sample uses the model 01
+	assert.Equal(t, sttZipSpec, msg.Frame)
+	assert.Equal(t, st.ErrUnsupportedModel, msg.ParsingError)
+	assert.Equal(t, STTReport, msg.Type)
+	assert.Equal(t, "100850001", msg.STT.DevID)
+
 	assert.False(t, p.Next())
-	assert.Equal(t, p.Error(), st.ErrZipUnsupported)
+	assert.Nil(t, p.Error())
+}
+
+func TestParseST600STTZip(t *testing.T) {
+	frame := zipFrame(st.ZipSTT, nil, sttZipSpec[43:55])
+	p := ParseBytes(frame, ParserOpts{})
+	assert.True(t, p.Next())
+	assert.Nil(t, p.Error())
+	msg := p.Msg()
+	require.NotNil(t, msg)
+
+	assert.Equal(t, frame, msg.Frame)
+	assert.Nil(t, msg.ParsingError)
+	assert.EqualValues(t, st.ST600R, msg.Model)
+	assert.Equal(t, STTReport, msg.Type)
+
+	expectedSTT := &StatusReport{
+		CommonReport:     zipCommon,
+		Mode:             st.ParkingMode,
+		MsgNum:           72,
+		DrivingHourMeter: 2000,
+		BackupVolt:       4.5,
+		RealTime:         true,
+		ADC:              12.35,
+	}
+	expectedSTT.Hdr = STTReport
+	equalSTT(t, expectedSTT, msg.STT)
+
+	assert.False(t, p.Next())
+}
+
+func TestParseST600STTZip3G(t *testing.T) {
+	cell := []byte{0x00, 0x1c, 0xbf, 0x75, 0x02, 0xda, 0x00, 0x02, 0x4e, 0x39, 42}
+	frame := zipFrame(st.ZipSTT, cell, sttZipSpec[43:55])
+	p := ParseBytes(frame, ParserOpts{})
+	assert.True(t, p.Next())
+	msg := p.Msg()
+	require.NotNil(t, msg)
+	assert.Nil(t, msg.ParsingError)
+
+	expectedCell := Cell{
+		Type: Cell3GType,
+		Cell3G: Cell3G{
+			CellID:      "001CBF75",
+			MCC:         "730",
+			MNC:         "2",
+			LAC:         "4E39",
+			SignalLevel: 42,
+		},
+	}
+	require.NotNil(t, msg.STT)
+	assert.Equal(t, expectedCell, msg.STT.Cell)
+	assert.Equal(t, float32(12.35), msg.STT.ADC)
+}
+
+func TestParseST600ReportsZip(t *testing.T) {
+	tail := []byte{0x00, 0x00, 0x07, 0xD0, 0x04, 0x50, 0x01, 0x0C, 0x35}
+	emg := zipFrame(st.ZipEMG, nil, append([]byte{1}, tail...))
+	evt := zipFrame(st.ZipEVT, nil, append([]byte{3}, tail...))
+	alt := zipFrame(st.ZipALT, nil, append([]byte{46}, tail...))
+	alv := []byte{st.STX, 0x00, 0x06, st.ZipALV, 0x10, 0x08, 0x50, 0x00, 0x10, st.ETX}
+	data := []byte("012345")
+	uexTail := append([]byte{0x00, byte(len(data))}, data...)
+	uexTail = append(uexTail, 0x2F, 0x00, 0x00, 0x07, 0xD0, 0x04, 0x50, 0x01)
+	uex := zipFrame(st.ZipUEX, nil, uexTail)
+
+	var buf []byte
+	for _, frame := range [][]byte{emg, evt, alt, alv, uex} {
+		buf = append(buf, frame...)
+	}
+
+	var msgs []*Msg
+	p := ParseBytes(buf, ParserOpts{})
+	for p.Next() {
+		require.Nil(t, p.Msg().ParsingError)
+		msgs = append(msgs, p.Msg())
+	}
+	require.Nil(t, p.Error())
+	require.Len(t, msgs, 5)
+
+	expectedCommon := zipCommon
+	expectedCommon.Hdr = EMGReport
+	equalEMG(t, &EmergencyReport{
+		CommonReport:     expectedCommon,
+		EmgID:            st.PanicButtonEmg,
+		DrivingHourMeter: 2000,
+		BackupVolt:       4.5,
+		RealTime:         true,
+		ADC:              12.35,
+	}, msgs[0].EMG)
+	assert.Equal(t, emg, msgs[0].Frame)
+
+	expectedCommon.Hdr = EVTReport
+	equalEVT(t, &EventReport{
+		CommonReport:     expectedCommon,
+		EvtID:            st.Input2GroundEvt,
+		DrivingHourMeter: 2000,
+		BackupVolt:       4.5,
+		RealTime:         true,
+		ADC:              12.35,
+	}, msgs[1].EVT)
+
+	expectedCommon.Hdr = ALTReport
+	equalALT(t, &AlertReport{
+		CommonReport:     expectedCommon,
+		AltID:            st.FastAccelerationFromDPAAlt,
+		DrivingHourMeter: 2000,
+		BackupVolt:       4.5,
+		RealTime:         true,
+		ADC:              12.35,
+	}, msgs[2].ALT)
+
+	assert.Equal(t, ALVReport, msgs[3].Type)
+	assert.Equal(t, "100850001", msgs[3].ALV.DevID)
+	assert.Equal(t, alv, msgs[3].Frame)
+
+	expectedCommon.Hdr = UEXReport
+	equalUEX(t, &ExtDataReport{
+		CommonReport:     expectedCommon,
+		Len:              6,
+		Data:             data,
+		Checksum:         0x2F,
+		DrivingHourMeter: 2000,
+		BackupVolt:       4.5,
+		RealTime:         true,
+	}, msgs[4].UEX)
+	assert.True(t, msgs[4].UEX.Valid())
+}
+
+func TestMarshalST600ReportsZip(t *testing.T) {
+	tail := []byte{0x00, 0x00, 0x07, 0xD0, 0x04, 0x50, 0x01, 0x0C, 0x35}
+	cell3G := []byte{0x00, 0x1c, 0xbf, 0x75, 0x02, 0xda, 0x00, 0x02, 0x4e, 0x39, 42}
+	uexTail := []byte{0x00, 0x06, '0', '1', '2', '3', '4', '5', 0x2F, 0x00, 0x00, 0x07, 0xD0, 0x04, 0x50, 0x01}
+	frames := [][]byte{
+		zipFrame(st.ZipSTT, nil, sttZipSpec[43:55]),
+		zipFrame(st.ZipSTT, cell3G, sttZipSpec[43:55]),
+		zipFrame(st.ZipEMG, nil, append([]byte{1}, tail...)),
+		zipFrame(st.ZipEVT, cell3G, append([]byte{3}, tail...)),
+		zipFrame(st.ZipALT, nil, append([]byte{46}, tail...)),
+		{st.STX, 0x00, 0x06, st.ZipALV, 0x10, 0x08, 0x50, 0x00, 0x10, st.ETX},
+		zipFrame(st.ZipUEX, nil, uexTail),
+		zipFrame(st.ZipUEX, cell3G, uexTail),
+	}
+
+	marshal := func(msg *Msg) []byte {
+		var marshaled []byte
+		var err error
+		switch msg.Type {
+		case STTReport:
+			marshaled, err = msg.STT.MarshalZip()
+		case EMGReport:
+			marshaled, err = msg.EMG.MarshalZip()
+		case EVTReport:
+			marshaled, err = msg.EVT.MarshalZip()
+		case ALTReport:
+			marshaled, err = msg.ALT.MarshalZip()
+		case ALVReport:
+			marshaled, err = msg.ALV.MarshalZip()
+		case UEXReport:
+			marshaled, err = msg.UEX.MarshalZip()
+		}
+		require.Nil(t, err)
+		return marshaled
+	}
+
+	for _, frame := range frames {
+		p := ParseBytes(frame, ParserOpts{})
+		require.True(t, p.Next())
+		msg := p.Msg()
+		require.Nil(t, msg.ParsingError)
+
+		// the frame could differ in the last decimal of the coordinates (rounded from float32), but the decoded
+		// report must be the same
+		marshaled := marshal(msg)
+		assert.Len(t, marshaled, len(frame))
+		p = ParseBytes(marshaled, ParserOpts{})
+		require.True(t, p.Next())
+		again := p.Msg()
+		require.Nil(t, again.ParsingError)
+		again.Frame = msg.Frame
+		assert.Equal(t, msg, again)
+		assert.Equal(t, marshaled, marshal(again))
+	}
+}
+
+func TestMarshalZipFenceAlert(t *testing.T) {
+	p := ParseBytes(zipFrame(st.ZipALT, nil, append([]byte{46}, 0x00, 0x00, 0x07, 0xD0, 0x04, 0x50, 0x01, 0x0C, 0x35)), ParserOpts{})
+	require.True(t, p.Next())
+	alt := p.Msg().ALT
+	require.NotNil(t, alt)
+	_, err := alt.MarshalZip()
+	require.Nil(t, err)
+
+	alt.Fence = st.CircleFence
+	alt.GroupID = 2
+	_, err = alt.MarshalZip()
+	assert.Equal(t, ErrZipFence, err)
 }
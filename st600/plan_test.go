@@ -0,0 +1,65 @@
+package st600
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const presetAFrame = "ST600CMD;Res;100850000;010;PresetA;NTW;0;internet;;;111.111.111.111;8600;;;;1234;RPT;60;180;120;60;3;0;0;;;;EVT;1;60;0;3;2;2;30;20;20;1;1;1;0;0;0;0;0;0;0;0;0;0;0;0;GSM;0;;;;;0;;;;;;;;SVC;1;120;0;0;0;0;1;1;1;0;0;0;0;ADP;U;T;2;9000;0;0;0;0;0;0;MSR;10;0.10;0.10;0.70;MBV;9.43;21.07;17.07;8.00;18.00;0.00;0.00;NPT;02;0;0;0;0;0;500;300;5;10;5;10;DEV;0;0;0;0;0;0;0;0\r"
+
+func presetConfig(t *testing.T) *st.DeviceConfig {
+	msg := parseCmd(t, presetAFrame)
+	require.NotNil(t, msg.CMD.Result.Config)
+	return msg.CMD.Result.Config
+}
+
+func TestConfigCommands(t *testing.T) {
+	current := presetConfig(t)
+
+	desired := presetConfig(t)
+	desired.NTW.ServerIP = "10.0.0.1"
+	desired.RPT.DrivingInterval = 60
+	desired.MBV.Levels[6] = 12.5
+	desired.DEV.Params[0] = 1
+
+	frames, err := ConfigCommands("100850000", current, desired)
+	require.Nil(t, err)
+	require.Len(t, frames, 3)
+	assert.Equal(t, "ST600RPT;100850000;02;180;60;60;3;0;0;0;0;0", string(frames[0]))
+	assert.Equal(t, "ST600MBV;100850000;02;9.43;21.07;17.07;8.00;18.00;0;12.5", string(frames[1]))
+	assert.Equal(t, "ST600NTW;100850000;02;0;internet;;;10.0.0.1;8600;;;;1234", string(frames[2]))
+
+	// no changes, no commands
+	frames, err = ConfigCommands("100850000", current, presetConfig(t))
+	require.Nil(t, err)
+	assert.Empty(t, frames)
+
+	// sections missing from desired are left as they are
+	frames, err = ConfigCommands("100850000", current, &st.DeviceConfig{MSR: &st.MSRParams{Interval: 600}})
+	require.Nil(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, "ST600MSR;100850000;02;600;0;0;0", string(frames[0]))
+}
+
+func TestConfigCommandsUnknownCurrent(t *testing.T) {
+	frames, err := ConfigCommands("100850000", nil, presetConfig(t))
+	require.Nil(t, err)
+	// the NPT section of PresetA is partial, so it isn't sent
+	require.Len(t, frames, 8)
+	for _, frame := range frames {
+		assert.NotEqual(t, "ST600NPT;", string(frame[:9]))
+	}
+	assert.Equal(t, "ST600NTW;", string(frames[7][:9]))
+}
+
+func TestConfigCommandsInvalid(t *testing.T) {
+	desired := presetConfig(t)
+	desired.SVC.Parking = 2
+	frames, err := ConfigCommands("100850000", presetConfig(t), desired)
+	assert.Nil(t, frames)
+	require.IsType(t, &st.ParamError{}, err)
+	assert.Equal(t, "SVC", err.(*st.ParamError).Cmd)
+}
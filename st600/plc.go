@@ -0,0 +1,178 @@
+package st600
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// Actions of the PLC command.
+const (
+	GetPolygonGroupInfo   = "GetPolygonGroupInfo"
+	GetPolygonGroupPos    = "GetPolygonGroupPos"
+	ErasePolygonGroup     = "ErasePolygonGroup"
+	GetPolygonServiceInfo = "GetPolygonServiceInfo"
+)
+
+// PolygonGroupInfo is the response of GetPolygonGroupInfo.
+type PolygonGroupInfo struct {
+	// Params holds the settings of the group, in the order of the protocol
+	Params      []string
+	VertexCount int
+	Name        string
+}
+
+// PolygonPos is a page of the vertices of a polygon, the response of GetPolygonGroupPos (see AssemblePolygon).
+type PolygonPos struct {
+	Page int
+
+	// VertexCount is the count of vertices of the whole polygon
+	VertexCount int
+	Vertices    []Vertex
+}
+
+// ST600PLC is a query (or an erase) of the polygonal geofences of a device (PLC command).
+type ST600PLC struct {
+	DevID  string
+	SwVer  string
+	Action string
+
+	// GroupID isn't used by GetPolygonServiceInfo
+	GroupID int
+
+	// Info, Pos and Service hold the content of the response, depending on the action
+	Info    *PolygonGroupInfo
+	Pos     *PolygonPos
+	Service *PolygonService
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR). An empty SwVer is sent as st.CmdVersion.
+func (plc *ST600PLC) Command() ([]byte, error) {
+	switch plc.Action {
+	case GetPolygonGroupInfo, GetPolygonGroupPos, ErasePolygonGroup:
+		if plc.GroupID < 1 || plc.GroupID > 99 {
+			return nil, &st.ParamError{Cmd: "PLC", Param: "GroupID", Value: plc.GroupID}
+		}
+	case GetPolygonServiceInfo:
+	default:
+		return nil, &st.ParamError{Cmd: "PLC", Param: "Action", Value: plc.Action}
+	}
+	return st.Command("ST600PLC", plc.DevID, plc.SwVer, st.ParamsFunc(func(w *st.AsciiWriter) {
+		w.Field(plc.Action)
+		if plc.Action != GetPolygonServiceInfo {
+			w.Field(strconv.Itoa(plc.GroupID))
+		}
+	}))
+}
+
+func parsePLC(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = PLCCmd
+
+	plc := &ST600PLC{}
+	msg.PLC = plc
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	plc.Resp, plc.DevID, plc.SwVer = resp, devID, swVer
+
+	if len(fields) == 0 {
+		msg.ParsingError = fmt.Errorf("invalid PLC parameters count: %d", len(fields))
+		return
+	}
+	// some responses have a space before the action
+	plc.Action = strings.TrimSpace(fields[0])
+	fields = fields[1:]
+
+	if plc.Action == GetPolygonServiceInfo {
+		if resp {
+			plc.Service, msg.ParsingError = decodePolygonService("PLC", fields)
+		}
+		return
+	}
+
+	switch plc.Action {
+	case GetPolygonGroupInfo, GetPolygonGroupPos, ErasePolygonGroup:
+	default:
+		msg.ParsingError = &st.ParamError{Cmd: "PLC", Param: "Action", Value: plc.Action}
+		return
+	}
+	if len(fields) == 0 {
+		msg.ParsingError = fmt.Errorf("invalid PLC parameters count: %d", len(fields))
+		return
+	}
+	var err error
+	if plc.GroupID, err = atoi("PLC", "GroupID", fields[0]); err != nil {
+		msg.ParsingError = err
+		return
+	}
+	if !resp {
+		if len(fields) != 1 {
+			msg.ParsingError = fmt.Errorf("invalid PLC parameters count: %d (expected 1)", len(fields))
+		}
+		return
+	}
+
+	switch plc.Action {
+	case GetPolygonGroupInfo:
+		plc.Info, msg.ParsingError = decodePolygonGroupInfo(fields[1:])
+	case GetPolygonGroupPos:
+		plc.Pos, msg.ParsingError = decodePolygonPos(fields[1:])
+	}
+}
+
+// decodePolygonGroupInfo decodes the response of GetPolygonGroupInfo, after the group: the settings of the group,
+// the count of vertices and the name.
+func decodePolygonGroupInfo(fields []string) (*PolygonGroupInfo, error) {
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid PLC parameters count: %d", len(fields))
+	}
+	count, err := atoi("PLC", "VertexCount", fields[len(fields)-2])
+	if err != nil {
+		return nil, err
+	}
+	return &PolygonGroupInfo{
+		Params:      fields[:len(fields)-2],
+		VertexCount: count,
+		Name:        fields[len(fields)-1],
+	}, nil
+}
+
+// decodePolygonPos decodes the response of GetPolygonGroupPos, after the group: the page, the count of vertices of
+// the polygon and the vertices of the page (pairs of latitude and longitude).
+func decodePolygonPos(fields []string) (*PolygonPos, error) {
+	if len(fields) < 2 || len(fields)%2 != 0 {
+		return nil, fmt.Errorf("invalid PLC parameters count: %d", len(fields))
+	}
+	page, err := atoi("PLC", "Page", fields[0])
+	if err != nil {
+		return nil, err
+	}
+	count, err := atoi("PLC", "VertexCount", fields[1])
+	if err != nil {
+		return nil, err
+	}
+	pos := &PolygonPos{
+		Page:        page,
+		VertexCount: count,
+	}
+	for i := 2; i < len(fields); i += 2 {
+		lat, err := atof("PLC", "Latitude", fields[i])
+		if err != nil {
+			return nil, err
+		}
+		lon, err := atof("PLC", "Longitude", fields[i+1])
+		if err != nil {
+			return nil, err
+		}
+		pos.Vertices = append(pos.Vertices, Vertex{Latitude: lat, Longitude: lon})
+	}
+	return pos, nil
+}
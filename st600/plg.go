@@ -0,0 +1,97 @@
+package st600
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600PLG is a frame of the PLG command (or of its response), one page of a polygonal geofence. See Polygon to
+// build the whole sequence of frames.
+type ST600PLG struct {
+	DevID   string
+	SwVer   string
+	Page    int
+	Pages   int
+	GroupID int
+
+	// Params holds the parameters of the page: the settings of the group in the first page, the vertices (pairs of
+	// longitude and latitude) in the following ones and the active flag in the last one
+	Params []string
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR). An empty SwVer is sent as st.CmdVersion.
+func (plg *ST600PLG) Command() ([]byte, error) {
+	if plg.Page < 1 || plg.Page > plg.Pages || plg.Pages > 99 {
+		return nil, &st.ParamError{Cmd: "PLG", Param: "Page", Value: plg.Page}
+	}
+	for _, p := range plg.Params {
+		if strings.ContainsAny(p, ";\r\n") {
+			return nil, &st.ParamError{Cmd: "PLG", Param: "Params", Value: p}
+		}
+	}
+	return st.Command("ST600PLG", plg.DevID, plg.SwVer, st.ParamsFunc(func(w *st.AsciiWriter) {
+		w.Field(strconv.Itoa(plg.Page))
+		w.Field(strconv.Itoa(plg.Pages))
+		w.Field(strconv.Itoa(plg.GroupID))
+		for _, p := range plg.Params {
+			w.Field(p)
+		}
+	}))
+}
+
+func parsePLG(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = PLGCmd
+
+	plg := &ST600PLG{}
+	msg.PLG = plg
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	plg.Resp, plg.DevID, plg.SwVer = resp, devID, swVer
+
+	if len(fields) < 4 {
+		msg.ParsingError = fmt.Errorf("invalid PLG parameters count: %d", len(fields))
+		return
+	}
+	var err error
+	if plg.Page, err = atoi("PLG", "Page", fields[0]); err != nil {
+		msg.ParsingError = err
+		return
+	}
+	if plg.Pages, err = atoi("PLG", "Pages", fields[1]); err != nil {
+		msg.ParsingError = err
+		return
+	}
+	if plg.GroupID, err = atoi("PLG", "GroupID", fields[2]); err != nil {
+		msg.ParsingError = err
+		return
+	}
+	plg.Params = fields[3:]
+}
+
+// atoi decodes an integer parameter of a command.
+func atoi(cmd, param, s string) (int, error) {
+	v, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, &st.ParamError{Cmd: cmd, Param: param, Value: s}
+	}
+	return v, nil
+}
+
+// atof decodes a float parameter of a command.
+func atof(cmd, param, s string) (float32, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 32)
+	if err != nil {
+		return 0, &st.ParamError{Cmd: cmd, Param: param, Value: s}
+	}
+	return float32(v), nil
+}
@@ -0,0 +1,77 @@
+package st600
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// PolygonService holds the settings of the polygonal geofences service.
+type PolygonService struct {
+	Active bool
+
+	// Interval is in seconds
+	Interval int
+}
+
+// ST600PLS holds the settings of the polygonal geofences service of a device (PLS command).
+type ST600PLS struct {
+	DevID string
+	SwVer string
+	PolygonService
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR). An empty SwVer is sent as st.CmdVersion.
+func (pls *ST600PLS) Command() ([]byte, error) {
+	if pls.Interval < 0 || pls.Interval > 65535 {
+		return nil, &st.ParamError{Cmd: "PLS", Param: "Interval", Value: pls.Interval}
+	}
+	return st.Command("ST600PLS", pls.DevID, pls.SwVer, st.ParamsFunc(func(w *st.AsciiWriter) {
+		w.Bit(pls.Active)
+		w.Field(strconv.Itoa(pls.Interval))
+	}))
+}
+
+// decodePolygonService decodes the active flag and the interval of the service.
+func decodePolygonService(cmd string, fields []string) (*PolygonService, error) {
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid %s parameters count: %d (expected 2)", cmd, len(fields))
+	}
+	active, err := atoi(cmd, "Active", fields[0])
+	if err != nil {
+		return nil, err
+	}
+	interval, err := atoi(cmd, "Interval", fields[1])
+	if err != nil {
+		return nil, err
+	}
+	return &PolygonService{
+		Active:   active == 1,
+		Interval: interval,
+	}, nil
+}
+
+func parsePLS(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = PLSCmd
+
+	pls := &ST600PLS{}
+	msg.PLS = pls
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	pls.Resp, pls.DevID, pls.SwVer = resp, devID, swVer
+
+	service, err := decodePolygonService("PLS", fields)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	pls.PolygonService = *service
+}
@@ -0,0 +1,211 @@
+package st600
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/larixsource/suntech/st"
+)
+
+var (
+	ErrPolygonGroups     = errors.New("polygon responses of more than one group")
+	ErrPolygonIncomplete = errors.New("incomplete polygon vertices")
+)
+
+// polygonPageVertices is the count of vertices sent in each PLG frame.
+const polygonPageVertices = 5
+
+// Vertex is a vertex of a polygonal geofence.
+type Vertex struct {
+	Latitude  float32
+	Longitude float32
+}
+
+// Polygon is a polygonal geofence group, stored in a device with a sequence of PLG commands (see Commands).
+type Polygon struct {
+	GroupID int
+	Active  bool
+
+	// Interval and Delay are in seconds
+	Interval int
+	Delay    int
+
+	// SpeedLimit enables the over speed alerts inside the polygon: above MaxSpeed, and back under ResumeSpeed (in
+	// km/h)
+	SpeedLimit  bool
+	MaxSpeed    float32
+	ResumeSpeed float32
+
+	// Params holds the remaining parameters of the group, in the order of the protocol
+	Params [4]float32
+
+	Name     string
+	Vertices []Vertex
+}
+
+func (p *Polygon) fail(param string, v interface{}) error {
+	return &st.ParamError{Cmd: "PLG", Param: param, Value: v}
+}
+
+// Validate checks the ranges of the parameters and the vertices. An active polygon needs at least 3 vertices.
+func (p *Polygon) Validate() error {
+	switch {
+	case p.GroupID < 1 || p.GroupID > 99:
+		return p.fail("GroupID", p.GroupID)
+	case p.Interval < 0 || p.Interval > 65535:
+		return p.fail("Interval", p.Interval)
+	case p.Delay < 0 || p.Delay > 65535:
+		return p.fail("Delay", p.Delay)
+	case p.MaxSpeed < 0 || p.MaxSpeed > 999:
+		return p.fail("MaxSpeed", p.MaxSpeed)
+	case p.ResumeSpeed < 0 || p.ResumeSpeed > 999:
+		return p.fail("ResumeSpeed", p.ResumeSpeed)
+	case len(p.Name) > 32 || strings.ContainsAny(p.Name, ";\r\n"):
+		return p.fail("Name", p.Name)
+	case p.Active && len(p.Vertices) < 3:
+		return p.fail("Vertices", len(p.Vertices))
+	}
+	for _, v := range p.Params {
+		if v < 0 || v > 65535 {
+			return p.fail("Params", v)
+		}
+	}
+	for _, v := range p.Vertices {
+		if v.Latitude < -90 || v.Latitude > 90 {
+			return p.fail("Latitude", v.Latitude)
+		}
+		if v.Longitude < -180 || v.Longitude > 180 {
+			return p.fail("Longitude", v.Longitude)
+		}
+	}
+	return nil
+}
+
+func formatBit(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func formatFloat(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
+
+// PLG splits the polygon into the PLG frames that store it in a device: the settings of the group, the vertices
+// (5 per frame) and the active flag. An inactive polygon without vertices is sent as a single frame, that disables
+// the group.
+func (p *Polygon) PLG(devID string) ([]*ST600PLG, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if !p.Active && len(p.Vertices) == 0 {
+		return []*ST600PLG{
+			{DevID: devID, Page: 1, Pages: 1, GroupID: p.GroupID, Params: []string{"0"}},
+		}, nil
+	}
+
+	pages := 2 + (len(p.Vertices)+polygonPageVertices-1)/polygonPageVertices
+	var frames []*ST600PLG
+	add := func(params []string) {
+		frames = append(frames, &ST600PLG{
+			DevID:   devID,
+			Page:    len(frames) + 1,
+			Pages:   pages,
+			GroupID: p.GroupID,
+			Params:  params,
+		})
+	}
+
+	settings := []string{
+		formatBit(p.Active),
+		strconv.Itoa(p.Interval),
+		strconv.Itoa(p.Delay),
+		formatBit(p.SpeedLimit),
+		formatFloat(p.MaxSpeed),
+		formatFloat(p.ResumeSpeed),
+	}
+	for _, v := range p.Params {
+		settings = append(settings, formatFloat(v))
+	}
+	if p.Name != "" {
+		settings = append(settings, p.Name)
+	}
+	add(settings)
+
+	for i := 0; i < len(p.Vertices); i += polygonPageVertices {
+		// every page has the same count of fields, the missing vertices are left empty
+		params := make([]string, 2*polygonPageVertices)
+		for j := 0; j < polygonPageVertices && i+j < len(p.Vertices); j++ {
+			v := p.Vertices[i+j]
+			params[2*j] = st.FormatCoord(v.Longitude)
+			params[2*j+1] = st.FormatCoord(v.Latitude)
+		}
+		add(params)
+	}
+
+	add([]string{formatBit(p.Active)})
+	return frames, nil
+}
+
+// Commands builds the frames (without the CR) of the PLG commands that store the polygon in a device, in the order
+// they must be sent.
+func (p *Polygon) Commands(devID string) ([][]byte, error) {
+	frames, err := p.PLG(devID)
+	if err != nil {
+		return nil, err
+	}
+	cmds := make([][]byte, 0, len(frames))
+	for _, f := range frames {
+		cmd, err := f.Command()
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, nil
+}
+
+// AssemblePolygon rebuilds a polygon from the responses of the GetPolygonGroupPos command (one per page, in any
+// order), so it can be compared with the one sent to the device. The name is taken from a GetPolygonGroupInfo
+// response, if there is one. Other responses are ignored.
+func AssemblePolygon(resps []*ST600PLC) (*Polygon, error) {
+	var polygon *Polygon
+	var pages []*PolygonPos
+	for _, r := range resps {
+		if r == nil || (r.Pos == nil && r.Info == nil) {
+			continue
+		}
+		if polygon == nil {
+			polygon = &Polygon{GroupID: r.GroupID}
+		}
+		if r.GroupID != polygon.GroupID {
+			return nil, ErrPolygonGroups
+		}
+		if r.Info != nil {
+			polygon.Name = r.Info.Name
+		}
+		if r.Pos != nil {
+			pages = append(pages, r.Pos)
+		}
+	}
+	if len(pages) == 0 {
+		return nil, ErrPolygonIncomplete
+	}
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Page < pages[j].Page
+	})
+	for i, page := range pages {
+		if page.Page != i+1 {
+			return nil, ErrPolygonIncomplete
+		}
+		polygon.Vertices = append(polygon.Vertices, page.Vertices...)
+	}
+	if len(polygon.Vertices) != pages[0].VertexCount {
+		return nil, ErrPolygonIncomplete
+	}
+	return polygon, nil
+}
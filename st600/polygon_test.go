@@ -0,0 +1,196 @@
+package st600
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseCmd parses a single frame, checking it has no errors.
+func parseCmd(t *testing.T, frame string) *Msg {
+	p := ParseString(frame, ParserOpts{})
+	require.True(t, p.Next())
+	require.Nil(t, p.Error())
+	msg := p.Msg()
+	require.NotNil(t, msg)
+	require.Nil(t, msg.ParsingError)
+	assert.Equal(t, frame, string(msg.Frame))
+	assert.False(t, p.Next())
+	return msg
+}
+
+func specPolygon() *Polygon {
+	return &Polygon{
+		GroupID:     1,
+		Active:      true,
+		Interval:    3600,
+		Delay:       60,
+		SpeedLimit:  true,
+		MaxSpeed:    80.5,
+		ResumeSpeed: 75,
+		Params:      [4]float32{28.5, 2, 30.33, 1},
+		Name:        "position 1",
+		Vertices: []Vertex{
+			{Latitude: 37.480648, Longitude: 126.885545},
+			{Latitude: 37.478162, Longitude: 126.887466},
+			{Latitude: 37.477506, Longitude: 126.885095},
+			{Latitude: 37.479005, Longitude: 126.884279},
+			{Latitude: 37.478885, Longitude: 126.883764},
+			{Latitude: 37.476655, Longitude: 126.884773},
+			{Latitude: 37.477710, Longitude: 126.888517},
+			{Latitude: 37.480520, Longitude: 126.886328},
+		},
+	}
+}
+
+func TestPolygonCommands(t *testing.T) {
+	cmds, err := specPolygon().Commands("100850000")
+	require.Nil(t, err)
+
+	// the same frames of the spec, with a DevID of 9 digits
+	require.Len(t, cmds, 4)
+	assert.Equal(t, "ST600PLG;100850000;02;1;4;1;1;3600;60;1;80.5;75;28.5;2;30.33;1;position 1", string(cmds[0]))
+	assert.Equal(t, "ST600PLG;100850000;02;4;4;1;1", string(cmds[3]))
+
+	// every frame is parsed back, the vertices (longitude and latitude) are compared as float32, because they
+	// aren't exactly the ones of the spec
+	var vertices []Vertex
+	for i, cmd := range cmds {
+		msg := parseCmd(t, string(cmd)+"\r")
+		assert.Equal(t, PLGCmd, msg.Type)
+		assert.Equal(t, i+1, msg.PLG.Page)
+		assert.Equal(t, 4, msg.PLG.Pages)
+		assert.Equal(t, 1, msg.PLG.GroupID)
+		if i == 0 || i == 3 {
+			continue
+		}
+		require.Len(t, msg.PLG.Params, 10)
+		for j := 0; j < 10 && msg.PLG.Params[j] != ""; j += 2 {
+			lon, err := atof("PLG", "Longitude", msg.PLG.Params[j])
+			require.Nil(t, err)
+			lat, err := atof("PLG", "Latitude", msg.PLG.Params[j+1])
+			require.Nil(t, err)
+			vertices = append(vertices, Vertex{Latitude: lat, Longitude: lon})
+		}
+	}
+	assert.Equal(t, specPolygon().Vertices, vertices)
+	assert.True(t, strings.HasSuffix(string(cmds[2]), ";;;;"))
+}
+
+func TestPolygonDisable(t *testing.T) {
+	polygon := &Polygon{GroupID: 1}
+	cmds, err := polygon.Commands("100850000")
+	require.Nil(t, err)
+	require.Len(t, cmds, 1)
+	assert.Equal(t, "ST600PLG;100850000;02;1;1;1;0", string(cmds[0]))
+}
+
+func TestPolygonErrors(t *testing.T) {
+	polygon := specPolygon()
+	polygon.Vertices = polygon.Vertices[:2]
+	_, err := polygon.Commands("100850000")
+	assert.Equal(t, &st.ParamError{Cmd: "PLG", Param: "Vertices", Value: 2}, err)
+
+	polygon = specPolygon()
+	polygon.Name = "a;b"
+	_, err = polygon.Commands("100850000")
+	assert.Equal(t, &st.ParamError{Cmd: "PLG", Param: "Name", Value: "a;b"}, err)
+
+	polygon = specPolygon()
+	polygon.Vertices[3].Longitude = 181
+	_, err = polygon.Commands("100850000")
+	assert.Equal(t, &st.ParamError{Cmd: "PLG", Param: "Longitude", Value: float32(181)}, err)
+
+	_, err = specPolygon().Commands("850000")
+	assert.Equal(t, st.ErrInvalidDevID, err)
+}
+
+func TestST600PLS(t *testing.T) {
+	pls := &ST600PLS{
+		DevID: "100850000",
+		PolygonService: PolygonService{
+			Active:   true,
+			Interval: 3600,
+		},
+	}
+	cmd, err := pls.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST600PLS;100850000;02;1;3600", string(cmd))
+
+	msg := parseCmd(t, "ST600PLS;Res;850000;104;1;3600\r")
+	assert.Equal(t, PLSCmd, msg.Type)
+	assert.Equal(t, &ST600PLS{
+		DevID:          "850000",
+		SwVer:          "104",
+		PolygonService: pls.PolygonService,
+		Resp:           true,
+	}, msg.PLS)
+}
+
+func TestST600PLC(t *testing.T) {
+	plc := &ST600PLC{DevID: "100850000", Action: GetPolygonGroupPos, GroupID: 1}
+	cmd, err := plc.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST600PLC;100850000;02;GetPolygonGroupPos;1", string(cmd))
+
+	plc = &ST600PLC{DevID: "100850000", Action: GetPolygonServiceInfo}
+	cmd, err = plc.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST600PLC;100850000;02;GetPolygonServiceInfo", string(cmd))
+
+	plc = &ST600PLC{DevID: "100850000", Action: "ErasePolygon", GroupID: 1}
+	_, err = plc.Command()
+	assert.Equal(t, &st.ParamError{Cmd: "PLC", Param: "Action", Value: "ErasePolygon"}, err)
+
+	msg := parseCmd(t, "ST600PLC;Res;850000;104;GetPolygonGroupInfo;1;1;1;60;0;3600;28.5;2;30.33;1;8;position 1\r")
+	assert.Equal(t, PLCCmd, msg.Type)
+	assert.Equal(t, GetPolygonGroupInfo, msg.PLC.Action)
+	assert.Equal(t, 1, msg.PLC.GroupID)
+	assert.Equal(t, &PolygonGroupInfo{
+		Params:      []string{"1", "1", "60", "0", "3600", "28.5", "2", "30.33", "1"},
+		VertexCount: 8,
+		Name:        "position 1",
+	}, msg.PLC.Info)
+
+	msg = parseCmd(t, "ST600PLC;Res;850000;104; ErasePolygonGroup;1\r")
+	assert.Equal(t, ErasePolygonGroup, msg.PLC.Action)
+	assert.Equal(t, 1, msg.PLC.GroupID)
+
+	msg = parseCmd(t, "ST600PLC;Res;850000;104;GetPolygonServiceInfo;1;3600\r")
+	assert.Equal(t, &PolygonService{Active: true, Interval: 3600}, msg.PLC.Service)
+}
+
+func TestAssemblePolygon(t *testing.T) {
+	// pages in any order, the spec example has a typo in the coordinates
+	frames := "ST600PLC;Res;850000;104;GetPolygonGroupPos;1;2;7;37.508887;126.901611;37.500000;126.900000\r" +
+		"ST600PLC;Res;850000;104;GetPolygonGroupInfo;1;1;1;60;0;3600;28.5;2;30.33;1;7;position 1\r" +
+		"ST600PLC;Res;850000;104;GetPolygonGroupPos;1;1;7;37.509683;126.882037;37.508841;126.880202;37.507981;126.80953;37.508926;126.882616;37.508887;126.901611\r"
+	var resps []*ST600PLC
+	p := ParseString(frames, ParserOpts{})
+	for p.Next() {
+		require.Nil(t, p.Msg().ParsingError)
+		resps = append(resps, p.Msg().PLC)
+	}
+	require.Nil(t, p.Error())
+
+	polygon, err := AssemblePolygon(resps)
+	require.Nil(t, err)
+	assert.Equal(t, 1, polygon.GroupID)
+	assert.Equal(t, "position 1", polygon.Name)
+	require.Len(t, polygon.Vertices, 7)
+	assert.Equal(t, Vertex{Latitude: 37.509683, Longitude: 126.882037}, polygon.Vertices[0])
+	assert.Equal(t, Vertex{Latitude: 37.5, Longitude: 126.9}, polygon.Vertices[6])
+
+	// missing page
+	_, err = AssemblePolygon(resps[:2])
+	assert.Equal(t, ErrPolygonIncomplete, err)
+
+	// other group
+	other := *resps[0]
+	other.GroupID = 2
+	_, err = AssemblePolygon(append(resps, &other))
+	assert.Equal(t, ErrPolygonGroups, err)
+}
@@ -0,0 +1,222 @@
+package st600
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+var (
+	ErrRouteSegments   = errors.New("invalid route segments")
+	ErrRouteIncomplete = errors.New("incomplete route")
+)
+
+// routeSegmentParams is the count of parameters of a route segment.
+const routeSegmentParams = 7
+
+// RouteSegment is a segment of a route (for the route deviation alerts): a corridor of Width meters, from a start
+// point to an end point.
+type RouteSegment struct {
+	Index          int
+	StartLatitude  float32
+	StartLongitude float32
+	Width          int
+	EndLatitude    float32
+	EndLongitude   float32
+	Option         int
+}
+
+// Validate checks the ranges of the parameters of the segment.
+func (seg *RouteSegment) Validate() error {
+	switch {
+	case seg.Index < 1 || seg.Index > 999:
+		return &st.ParamError{Cmd: "STR", Param: "Index", Value: seg.Index}
+	case !validLat(seg.StartLatitude):
+		return &st.ParamError{Cmd: "STR", Param: "StartLatitude", Value: seg.StartLatitude}
+	case !validLon(seg.StartLongitude):
+		return &st.ParamError{Cmd: "STR", Param: "StartLongitude", Value: seg.StartLongitude}
+	case seg.Width < 1 || seg.Width > 65535:
+		return &st.ParamError{Cmd: "STR", Param: "Width", Value: seg.Width}
+	case !validLat(seg.EndLatitude):
+		return &st.ParamError{Cmd: "STR", Param: "EndLatitude", Value: seg.EndLatitude}
+	case !validLon(seg.EndLongitude):
+		return &st.ParamError{Cmd: "STR", Param: "EndLongitude", Value: seg.EndLongitude}
+	case seg.Option < 0 || seg.Option > 9:
+		return &st.ParamError{Cmd: "STR", Param: "Option", Value: seg.Option}
+	}
+	return nil
+}
+
+func (seg *RouteSegment) EncodeParams(w *st.AsciiWriter) {
+	w.Field(strconv.Itoa(seg.Index))
+	w.Field(st.FormatCoord(seg.StartLatitude))
+	w.Field(st.FormatCoord(seg.StartLongitude))
+	w.Field(strconv.Itoa(seg.Width))
+	w.Field(st.FormatCoord(seg.EndLatitude))
+	w.Field(st.FormatCoord(seg.EndLongitude))
+	w.Field(strconv.Itoa(seg.Option))
+}
+
+func (seg *RouteSegment) DecodeParams(fields []string) error {
+	if len(fields) != routeSegmentParams {
+		return fmt.Errorf("invalid route segment parameters count: %d (expected %d)", len(fields), routeSegmentParams)
+	}
+	var err error
+	if seg.Index, err = atoi("STR", "Index", fields[0]); err != nil {
+		return err
+	}
+	if seg.StartLatitude, err = atoc("STR", "StartLatitude", fields[1]); err != nil {
+		return err
+	}
+	if seg.StartLongitude, err = atoc("STR", "StartLongitude", fields[2]); err != nil {
+		return err
+	}
+	if seg.Width, err = atoi("STR", "Width", fields[3]); err != nil {
+		return err
+	}
+	if seg.EndLatitude, err = atoc("STR", "EndLatitude", fields[4]); err != nil {
+		return err
+	}
+	if seg.EndLongitude, err = atoc("STR", "EndLongitude", fields[5]); err != nil {
+		return err
+	}
+	seg.Option, err = atoi("STR", "Option", fields[6])
+	return err
+}
+
+// atoc decodes a coordinate, that could have spaces after the sign or the point (like "+0. 222333").
+func atoc(cmd, param, s string) (float32, error) {
+	return atof(cmd, param, strings.Replace(s, " ", "", -1))
+}
+
+func validLat(lat float32) bool {
+	return lat >= -90 && lat <= 90
+}
+
+func validLon(lon float32) bool {
+	return lon >= -180 && lon <= 180
+}
+
+// ST600STR sets a segment of the route of a device (STR command).
+type ST600STR struct {
+	DevID string
+	SwVer string
+	RouteSegment
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the segment. An empty SwVer is sent as
+// st.CmdVersion.
+func (str *ST600STR) Command() ([]byte, error) {
+	if err := str.Validate(); err != nil {
+		return nil, err
+	}
+	return st.Command("ST600STR", str.DevID, str.SwVer, &str.RouteSegment)
+}
+
+func parseSTR(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = STRCmd
+
+	str := &ST600STR{}
+	msg.STR = str
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	str.Resp, str.DevID, str.SwVer = resp, devID, swVer
+	msg.ParsingError = str.DecodeParams(fields)
+}
+
+// ST600GTR requests the route of a device (GTR command). The device sends a response for each segment (see
+// AssembleRoute).
+type ST600GTR struct {
+	DevID string
+	SwVer string
+
+	// More is true when other responses follow this one
+	More bool
+
+	// Segment is the segment sent by a response
+	Segment *RouteSegment
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR). An empty SwVer is sent as st.CmdVersion.
+func (gtr *ST600GTR) Command() ([]byte, error) {
+	return st.Command("ST600GTR", gtr.DevID, gtr.SwVer, st.ParamsFunc(func(*st.AsciiWriter) {}))
+}
+
+func parseGTR(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = GTRCmd
+
+	gtr := &ST600GTR{}
+	msg.GTR = gtr
+
+	resp, devID, swVer, fields, ok := parseCmdFields(lex, msg)
+	if !ok {
+		return
+	}
+	gtr.Resp, gtr.DevID, gtr.SwVer = resp, devID, swVer
+	if !resp {
+		if len(fields) != 0 {
+			msg.ParsingError = fmt.Errorf("invalid GTR parameters count: %d (expected 0)", len(fields))
+		}
+		return
+	}
+
+	if len(fields) != routeSegmentParams+1 {
+		msg.ParsingError = fmt.Errorf("invalid GTR parameters count: %d (expected %d)", len(fields), routeSegmentParams+1)
+		return
+	}
+	more, err := atoi("GTR", "More", fields[0])
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	gtr.More = more == 1
+	seg := &RouteSegment{}
+	if msg.ParsingError = seg.DecodeParams(fields[1:]); msg.ParsingError != nil {
+		return
+	}
+	gtr.Segment = seg
+}
+
+// AssembleRoute joins the segments of the responses of GTR, ordered by their index. The last response is the only
+// one without More.
+func AssembleRoute(resps []*ST600GTR) ([]RouteSegment, error) {
+	if len(resps) == 0 {
+		return nil, ErrRouteIncomplete
+	}
+	route := make([]RouteSegment, 0, len(resps))
+	for i, gtr := range resps {
+		if !gtr.Resp || gtr.Segment == nil {
+			return nil, ErrRouteSegments
+		}
+		if last := i == len(resps)-1; last == gtr.More {
+			if last {
+				return nil, ErrRouteIncomplete
+			}
+			return nil, ErrRouteSegments
+		}
+		route = append(route, *gtr.Segment)
+	}
+	sort.Slice(route, func(i, j int) bool {
+		return route[i].Index < route[j].Index
+	})
+	for i := 1; i < len(route); i++ {
+		if route[i].Index == route[i-1].Index {
+			return nil, ErrRouteSegments
+		}
+	}
+	return route, nil
+}
@@ -0,0 +1,126 @@
+package st600
+
+import (
+	"testing"
+
+	"github.com/larixsource/suntech/st"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSTR(t *testing.T) {
+	str := ST600STR{
+		DevID: "100850000",
+		RouteSegment: RouteSegment{
+			Index:          1,
+			StartLatitude:  22.5,
+			StartLongitude: -0.25,
+			Width:          100,
+			EndLatitude:    22.125,
+			EndLongitude:   0.75,
+		},
+	}
+	b, err := str.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST600STR;100850000;02;1;+22.500000;-0.250000;100;+22.125000;+0.750000;0", string(b))
+	msg := parseCmd(t, string(b)+"\r")
+	assert.Equal(t, str.RouteSegment, msg.STR.RouteSegment)
+
+	// the spec frames have a space in a longitude
+	msg = parseCmd(t, "ST600STR;Res;100850000;010;1;+22.123456;-0.222222;100;+22.127890;+0. 222333;0\r")
+	assert.Equal(t, STRCmd, msg.Type)
+	require.NotNil(t, msg.STR)
+	assert.True(t, msg.STR.Resp)
+	assert.Equal(t, 100, msg.STR.Width)
+	assert.InDelta(t, 22.123456, msg.STR.StartLatitude, 0.00001)
+	assert.InDelta(t, 0.222333, msg.STR.EndLongitude, 0.00001)
+
+	testCases := []struct {
+		seg   func(*RouteSegment)
+		param string
+	}{
+		{seg: func(s *RouteSegment) { s.Index = 0 }, param: "Index"},
+		{seg: func(s *RouteSegment) { s.StartLatitude = 91 }, param: "StartLatitude"},
+		{seg: func(s *RouteSegment) { s.EndLongitude = -181 }, param: "EndLongitude"},
+		{seg: func(s *RouteSegment) { s.Width = 0 }, param: "Width"},
+	}
+	for _, tc := range testCases {
+		invalid := str
+		tc.seg(&invalid.RouteSegment)
+		_, err := invalid.Command()
+		require.IsType(t, &st.ParamError{}, err)
+		assert.Equal(t, tc.param, err.(*st.ParamError).Param)
+	}
+}
+
+func TestGTR(t *testing.T) {
+	gtr := ST600GTR{DevID: "100850000"}
+	b, err := gtr.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST600GTR;100850000;02", string(b))
+
+	// responses out of order
+	frames := []string{
+		"ST600GTR;Res;100850000;010;1;3;+22.133456;-0.222222;100;+22.137890;+0. 222333;0\r",
+		"ST600GTR;Res;100850000;010;0;1;+22.123456;-0.222222;100;+22.127890;+0. 222333;0\r",
+	}
+	var resps []*ST600GTR
+	for _, frame := range frames {
+		msg := parseCmd(t, frame)
+		assert.Equal(t, GTRCmd, msg.Type)
+		resps = append(resps, msg.GTR)
+	}
+	assert.True(t, resps[0].More)
+	assert.False(t, resps[1].More)
+
+	route, err := AssembleRoute(resps)
+	require.Nil(t, err)
+	require.Len(t, route, 2)
+	assert.Equal(t, 1, route[0].Index)
+	assert.Equal(t, 3, route[1].Index)
+	assert.InDelta(t, 22.13789, route[1].EndLatitude, 0.00001)
+
+	_, err = AssembleRoute(resps[:1])
+	assert.Equal(t, ErrRouteIncomplete, err)
+	_, err = AssembleRoute([]*ST600GTR{resps[1], resps[0]})
+	assert.Equal(t, ErrRouteSegments, err)
+	_, err = AssembleRoute([]*ST600GTR{resps[0], resps[0], resps[1]})
+	assert.Equal(t, ErrRouteSegments, err)
+}
+
+func TestCTR(t *testing.T) {
+	ctr := ST600CTR{DevID: "100850000", Value: 1}
+	b, err := ctr.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST600CTR;100850000;02;1", string(b))
+
+	ctr.Read = true
+	b, err = ctr.Command()
+	require.Nil(t, err)
+	assert.Equal(t, "ST600CTR;100850000;02;R;1", string(b))
+	msg := parseCmd(t, string(b)+"\r")
+	assert.Equal(t, &ST600CTR{DevID: "100850000", SwVer: "02", Read: true, Value: 1}, msg.CTR)
+
+	msg = parseCmd(t, "ST600CTR;Res;100850000;010;1\r")
+	assert.Equal(t, &ST600CTR{DevID: "100850000", SwVer: "010", Value: 1, Resp: true}, msg.CTR)
+
+	msg = parseCmd(t, "ST600CTR;100850000;02;R0\r")
+	assert.Equal(t, &ST600CTR{DevID: "100850000", SwVer: "02", Read: true, Value: 0, Resp: true}, msg.CTR)
+}
+
+func TestRouteErrors(t *testing.T) {
+	frames := []string{
+		"ST600STR;Res;100850000;010;1;+22.123456;-0.222222;100\r",
+		"ST600STR;Res;100850000;010;1;+22.123456;-0.222222;A;+22.127890;+0.222333;0\r",
+		"ST600GTR;Res;100850000;010;1;+22.123456;-0.222222;100;+22.127890;+0.222333;0\r",
+		"ST600GTR;100850000;02;1\r",
+		"ST600CTR;100850000;02;RX\r",
+		"ST600CTR;100850000;02;1;2;3\r",
+	}
+	for _, frame := range frames {
+		p := ParseString(frame, ParserOpts{})
+		require.True(t, p.Next())
+		assert.NotNil(t, p.Msg().ParsingError, frame)
+		assert.Equal(t, frame, string(p.Msg().Frame))
+	}
+}
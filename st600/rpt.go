@@ -0,0 +1,37 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600RPT holds the report parameters of a device (RPT command).
+type ST600RPT struct {
+	DevID string
+	SwVer string
+	st.RPTParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (rpt *ST600RPT) Command() ([]byte, error) {
+	return st.Command("ST600RPT", rpt.DevID, rpt.SwVer, &rpt.RPTParams)
+}
+
+func parseRPT(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = RPTCmd
+
+	rpt := &ST600RPT{}
+	msg.RPT = rpt
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	rpt.Resp, rpt.DevID, rpt.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &rpt.RPTParams)
+}
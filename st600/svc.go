@@ -0,0 +1,37 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ST600SVC holds the service parameters of a device (SVC command).
+type ST600SVC struct {
+	DevID string
+	SwVer string
+	st.SVCParams
+
+	// Resp is true when this is a response
+	Resp bool
+}
+
+// Command builds the frame of the command (without the CR), validating the parameters. An empty SwVer is sent as
+// st.CmdVersion.
+func (svc *ST600SVC) Command() ([]byte, error) {
+	return st.Command("ST600SVC", svc.DevID, svc.SwVer, &svc.SVCParams)
+}
+
+func parseSVC(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = SVCCmd
+
+	svc := &ST600SVC{}
+	msg.SVC = svc
+
+	resp, devID, swVer, ok := parseCmdHdr(lex, msg)
+	if !ok {
+		return
+	}
+	svc.Resp, svc.DevID, svc.SwVer = resp, devID, swVer
+
+	parseParams(lex, msg, &svc.SVCParams)
+}
@@ -36,6 +36,7 @@ const (
 	DEXReport
 
 	CMD
+	DPACmd
 )
 
 type Msg struct {
@@ -51,7 +52,47 @@ type Msg struct {
 	ALV *AliveReport
 	UEX *ExtDataReport
 
+	CGF    *ST600CGF
+	NTW    *ST600NTW
+	RPT    *ST600RPT
+	EVTCmd *ST600EVT
+	GSM    *ST600GSM
+	SVC    *ST600SVC
+	MBV    *ST600MBV
+	MSR    *ST600MSR
+	ADP    *ST600ADP
+	NPT    *ST600NPT
+	PLG    *ST600PLG
+	PLS    *ST600PLS
+	PLC    *ST600PLC
+	CMD    *ST600CMD
+	DEX    *ST600DEX
+	STR    *ST600STR
+	GTR    *ST600GTR
+	CTR    *ST600CTR
+	DPA    *ST600DPA
+	LTM    *ST600LTM
+
 	Frame []byte
 
 	ParsingError error
 }
+
+// Report returns the report of the message (STT, EMG, EVT, ALT or UEX), regardless of its Type, or nil when the
+// message isn't a report. The Alive Report doesn't have a position, so it isn't returned either.
+func (m *Msg) Report() st.Report {
+	switch {
+	case m.STT != nil:
+		return m.STT
+	case m.EMG != nil:
+		return m.EMG
+	case m.EVT != nil:
+		return m.EVT
+	case m.ALT != nil:
+		return m.ALT
+	case m.UEX != nil:
+		return m.UEX
+	default:
+		return nil
+	}
+}
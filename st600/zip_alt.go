@@ -0,0 +1,80 @@
+package st600
+
+import (
+	"errors"
+
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+// ErrZipFence is returned when marshaling a zip ALT frame of a geofence group alert, as its AltID can't hold the
+// Fence and GroupID.
+var ErrZipFence = errors.New("geofence group of an alert unsupported in zip frames")
+
+func parseALTZip(lex *lexer.Lexer, msg *Msg, cell3G bool) {
+	msg.Type = ALTReport
+
+	alt := &AlertReport{}
+	msg.ALT = alt
+	alt.Hdr = ALTReport
+
+	parseCommonZip(lex, msg, &alt.CommonReport, cell3G)
+	if msg.ParsingError != nil {
+		return
+	}
+
+	altID, err := st.ZipAltID(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	alt.AltID = altID
+
+	hmeter, err := st.ZipDrivingHourMeter(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	alt.DrivingHourMeter = hmeter
+
+	backupVolt, err := st.ZipBackupVolt(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	alt.BackupVolt = backupVolt
+
+	realTime, err := st.ZipBit(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	alt.RealTime = realTime
+
+	adc, err := st.ZipADC(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	alt.ADC = adc
+
+	return
+}
+
+// MarshalZip returns the zip frame of the report. Fields that can't be represented in a zip frame are reported as
+// an error.
+func (alt *AlertReport) MarshalZip() ([]byte, error) {
+	if alt.Fence != st.NoFence {
+		return nil, ErrZipFence
+	}
+	var w st.ZipWriter
+	if err := writeZipCommon(&w, &alt.CommonReport); err != nil {
+		return nil, err
+	}
+	w.AltID(alt.AltID)
+	w.DrivingHourMeter(alt.DrivingHourMeter)
+	w.BackupVolt(alt.BackupVolt)
+	w.Bit(alt.RealTime)
+	w.ADC(alt.ADC)
+	return w.Frame(st.ZipALT)
+}
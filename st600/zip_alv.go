@@ -0,0 +1,31 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+func parseALVZip(lex *lexer.Lexer, msg *Msg) {
+	msg.Type = ALVReport
+
+	alv := &AliveReport{
+		Hdr: ALVReport,
+	}
+	msg.ALV = alv
+
+	devID, err := st.ZipDevID(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	alv.DevID = devID
+
+	return
+}
+
+// MarshalZip returns the zip frame of the report.
+func (alv *AliveReport) MarshalZip() ([]byte, error) {
+	var w st.ZipWriter
+	w.DevID(alv.DevID)
+	return w.Frame(st.ZipALV)
+}
@@ -0,0 +1,71 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+func parseEMGZip(lex *lexer.Lexer, msg *Msg, cell3G bool) {
+	msg.Type = EMGReport
+
+	emg := &EmergencyReport{}
+	msg.EMG = emg
+	emg.Hdr = EMGReport
+
+	parseCommonZip(lex, msg, &emg.CommonReport, cell3G)
+	if msg.ParsingError != nil {
+		return
+	}
+
+	emgID, err := st.ZipEmgID(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	emg.EmgID = emgID
+
+	hmeter, err := st.ZipDrivingHourMeter(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	emg.DrivingHourMeter = hmeter
+
+	backupVolt, err := st.ZipBackupVolt(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	emg.BackupVolt = backupVolt
+
+	realTime, err := st.ZipBit(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	emg.RealTime = realTime
+
+	adc, err := st.ZipADC(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	emg.ADC = adc
+
+	return
+}
+
+// MarshalZip returns the zip frame of the report. Fields that can't be represented in a zip frame are reported as
+// an error.
+func (emg *EmergencyReport) MarshalZip() ([]byte, error) {
+	var w st.ZipWriter
+	if err := writeZipCommon(&w, &emg.CommonReport); err != nil {
+		return nil, err
+	}
+	w.EmgID(emg.EmgID)
+	w.DrivingHourMeter(emg.DrivingHourMeter)
+	w.BackupVolt(emg.BackupVolt)
+	w.Bit(emg.RealTime)
+	w.ADC(emg.ADC)
+	return w.Frame(st.ZipEMG)
+}
@@ -0,0 +1,71 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+func parseEVTZip(lex *lexer.Lexer, msg *Msg, cell3G bool) {
+	msg.Type = EVTReport
+
+	evt := &EventReport{}
+	msg.EVT = evt
+	evt.Hdr = EVTReport
+
+	parseCommonZip(lex, msg, &evt.CommonReport, cell3G)
+	if msg.ParsingError != nil {
+		return
+	}
+
+	evtID, err := st.ZipEvtID(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	evt.EvtID = evtID
+
+	hmeter, err := st.ZipDrivingHourMeter(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	evt.DrivingHourMeter = hmeter
+
+	backupVolt, err := st.ZipBackupVolt(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	evt.BackupVolt = backupVolt
+
+	realTime, err := st.ZipBit(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	evt.RealTime = realTime
+
+	adc, err := st.ZipADC(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	evt.ADC = adc
+
+	return
+}
+
+// MarshalZip returns the zip frame of the report. Fields that can't be represented in a zip frame are reported as
+// an error.
+func (evt *EventReport) MarshalZip() ([]byte, error) {
+	var w st.ZipWriter
+	if err := writeZipCommon(&w, &evt.CommonReport); err != nil {
+		return nil, err
+	}
+	w.EvtID(evt.EvtID)
+	w.DrivingHourMeter(evt.DrivingHourMeter)
+	w.BackupVolt(evt.BackupVolt)
+	w.Bit(evt.RealTime)
+	w.ADC(evt.ADC)
+	return w.Frame(st.ZipEVT)
+}
@@ -0,0 +1,79 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+func parseSTTZip(lex *lexer.Lexer, msg *Msg, cell3G bool) {
+	msg.Type = STTReport
+
+	stt := &StatusReport{}
+	msg.STT = stt
+	stt.Hdr = STTReport
+
+	parseCommonZip(lex, msg, &stt.CommonReport, cell3G)
+	if msg.ParsingError != nil {
+		return
+	}
+
+	mode, err := st.ZipMode(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.Mode = mode
+
+	msgNum, err := st.ZipMsgNum(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.MsgNum = msgNum
+
+	hmeter, err := st.ZipDrivingHourMeter(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.DrivingHourMeter = hmeter
+
+	backupVolt, err := st.ZipBackupVolt(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.BackupVolt = backupVolt
+
+	realTime, err := st.ZipBit(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.RealTime = realTime
+
+	adc, err := st.ZipADC(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	stt.ADC = adc
+
+	return
+}
+
+// MarshalZip returns the zip frame of the report. Fields that can't be represented in a zip frame are reported as
+// an error.
+func (stt *StatusReport) MarshalZip() ([]byte, error) {
+	var w st.ZipWriter
+	if err := writeZipCommon(&w, &stt.CommonReport); err != nil {
+		return nil, err
+	}
+	w.Mode(stt.Mode)
+	w.MsgNum(stt.MsgNum)
+	w.DrivingHourMeter(stt.DrivingHourMeter)
+	w.BackupVolt(stt.BackupVolt)
+	w.Bit(stt.RealTime)
+	w.ADC(stt.ADC)
+	return w.Frame(st.ZipSTT)
+}
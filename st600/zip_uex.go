@@ -0,0 +1,72 @@
+package st600
+
+import (
+	"github.com/larixsource/suntech/lexer"
+	"github.com/larixsource/suntech/st"
+)
+
+func parseUEXZip(lex *lexer.Lexer, msg *Msg, cell3G bool) {
+	msg.Type = UEXReport
+
+	uex := &ExtDataReport{}
+	msg.UEX = uex
+	uex.Hdr = UEXReport
+
+	parseCommonZip(lex, msg, &uex.CommonReport, cell3G)
+	if msg.ParsingError != nil {
+		return
+	}
+
+	length, data, err := st.ZipData(lex)
+	uex.Len = length
+	uex.Data = data
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+
+	chk, err := st.ZipChecksum(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	uex.Checksum = chk
+
+	hmeter, err := st.ZipDrivingHourMeter(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	uex.DrivingHourMeter = hmeter
+
+	backupVolt, err := st.ZipBackupVolt(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	uex.BackupVolt = backupVolt
+
+	realTime, err := st.ZipBit(lex)
+	if err != nil {
+		msg.ParsingError = err
+		return
+	}
+	uex.RealTime = realTime
+
+	return
+}
+
+// MarshalZip returns the zip frame of the report. Len is taken from the length of Data, and Checksum is written as
+// is (see Valid). Fields that can't be represented in a zip frame are reported as an error.
+func (uex *ExtDataReport) MarshalZip() ([]byte, error) {
+	var w st.ZipWriter
+	if err := writeZipCommon(&w, &uex.CommonReport); err != nil {
+		return nil, err
+	}
+	w.Data(uex.Data)
+	w.Checksum(uex.Checksum)
+	w.DrivingHourMeter(uex.DrivingHourMeter)
+	w.BackupVolt(uex.BackupVolt)
+	w.Bit(uex.RealTime)
+	return w.Frame(st.ZipUEX)
+}